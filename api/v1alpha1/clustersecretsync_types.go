@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].message"
+// +kubebuilder:printcolumn:name="LastTransition",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].lastTransitionTime"
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=".spec.suspend"
+//
+// ClusterSecretSync is the Schema for the clustersecretsyncs API.
+// It is the cluster-scoped variant of SecretSync meant for platform admins, it can read a source
+// from any namespace without the source opting in to being exported.
+type ClusterSecretSync struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SecretSyncSpec   `json:"spec,omitempty"`
+	Status SecretSyncStatus `json:"status,omitempty"`
+}
+
+// GetSpec returns the spec of the ClusterSecretSync.
+func (s *ClusterSecretSync) GetSpec() *SecretSyncSpec {
+	return &s.Spec
+}
+
+// GetStatus returns the status of the ClusterSecretSync.
+func (s *ClusterSecretSync) GetStatus() *SecretSyncStatus {
+	return &s.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSecretSyncList contains a list of ClusterSecretSync.
+type ClusterSecretSyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterSecretSync `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterSecretSync{}, &ClusterSecretSyncList{})
+}
@@ -17,33 +17,387 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// SecretSyncSpec defines the desired state of SecretSync.
+// SecretSyncSpec defines the desired state of SecretSync and ClusterSecretSync.
+// +kubebuilder:validation:XValidation:rule="(has(self.targetNamespaces) && size(self.targetNamespaces) > 0) || has(self.targetNamespaceSelector)",message="at least one of targetNamespaces or targetNamespaceSelector must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.sourceName) != has(self.sources)",message="exactly one of sourceName or sources must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.sources) || has(self.targetName)",message="targetName must be set when sources is set"
 type SecretSyncSpec struct {
+	// sourceKind is the kind of the source object to sync, either Secret or ConfigMap.
+	// The copies in the target namespaces are of the same kind as the source.
+	// +kubebuilder:default=Secret
+	// +optional
+	SourceKind SourceKind `json:"sourceKind,omitempty"`
+
 	// sourceName is the name of the source Secret to sync.
-	// +kubebuilder:validation:Required
+	// Exactly one of sourceName and sources must be set.
 	// +kubebuilder:validation:MinLength=1
-	SourceName string `json:"sourceName"`
+	// +optional
+	SourceName string `json:"sourceName,omitempty"`
+
+	// sources lists several source objects that are merged into a single copy, for example to assemble
+	// the credentials of an application from secrets owned by different teams.
+	// The sources are merged in order, a key of a later source wins over the same key of an earlier one.
+	// spec.data and spec.template are applied to the merged data, and targetName must be set.
+	// Exactly one of sourceName and sources must be set.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	// +optional
+	Sources []SourceRef `json:"sources,omitempty"`
 
-	// sourceNamespace is the namespace of the source secret
+	// sourceNamespace is the namespace of the source secret, and the default namespace of the entries of sources.
+	// For a namespaced SecretSync this must be the namespace of the SecretSync itself, unless the
+	// source opts in to being exported with the secretsync.example.com/export-to annotation.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinLength=1
 	SourceNamespace string `json:"sourceNamespace"`
 	//
 	// targetNamespaces is a list of namespaces where the source Secret should be copied to
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:MinItems=1
-	TargetNamespaces []string `json:"targetNamespaces"`
+	// +optional
+	TargetNamespaces []string `json:"targetNamespaces,omitempty"`
+
+	// targetNamespaceSelector selects the namespaces where the source Secret should be copied to
+	// based on their labels. It is resolved against the live Namespace objects on every reconcile
+	// and the matching namespaces are added to the targetNamespaces list.
+	// The source namespace is never selected, even if its labels match.
+	// +optional
+	TargetNamespaceSelector *metav1.LabelSelector `json:"targetNamespaceSelector,omitempty"`
+
+	// targetName is the name of the copies in the target namespaces.
+	// If it is not set, the copies have the same name as the source.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +optional
+	TargetName string `json:"targetName,omitempty"`
+
+	// targetNameOverrides maps a target namespace to the name of the copy in that namespace,
+	// it takes precedence over targetName for the listed namespaces.
+	// +optional
+	TargetNameOverrides map[string]string `json:"targetNameOverrides,omitempty"`
+
+	// prunePolicy decides what happens to a copy in a namespace that is no longer a target,
+	// for example because it was removed from targetNamespaces or stopped matching the targetNamespaceSelector.
+	// Delete removes the copy, Orphan strips the ownership labels and annotations and leaves the copy in place.
+	// Copies in a namespace the owner of the source or of the namespace refused are always deleted.
+	// +kubebuilder:default=Delete
+	// +optional
+	PrunePolicy PrunePolicy `json:"prunePolicy,omitempty"`
+
+	// deletionPolicy decides what happens to the copies when the SecretSync is deleted.
+	// Delete removes the copies, Orphan strips the ownership labels and annotations and leaves the copies in place,
+	// for example to move a SecretSync to another namespace without removing the copies in between.
+	// +kubebuilder:default=Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// conflictPolicy decides what happens when an object with the name of the copy already exists
+	// in a target namespace and is not managed by the controller.
+	// Fail refuses to touch it, Adopt takes it over if its data already matches the source,
+	// and Overwrite takes it over and replaces its data.
+	// Objects owned by another SecretSync or ClusterSecretSync are never taken over.
+	// +kubebuilder:default=Fail
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// refreshInterval re-syncs every target periodically, in addition to the syncs triggered by changes
+	// of the source, the targets and the namespaces. Copies that were changed or deleted by someone else
+	// are restored. If it is not set, there is no periodic re-sync.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// suspend pauses the sync, for example to stop a broken or compromised source from reaching the targets
+	// during an incident. While it is true no copy is written or pruned and the copies keep their current content,
+	// deleting the SecretSync still cleans up the copies according to deletionPolicy.
+	// The sync resumes when it is cleared.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// rolloutTargets lists the kinds of workloads that are restarted when the content of a copy changes,
+	// for example to pick up rotated credentials that are consumed as environment variables.
+	// Only the workloads in the namespace of the copy that reference it through env, envFrom or volumes
+	// are restarted, by setting the secretsync.example.com/restarted-at annotation on their pod template.
+	// +listType=set
+	// +optional
+	RolloutTargets []RolloutTarget `json:"rolloutTargets,omitempty"`
+
+	// data selects and renames the keys of the source that are copied to the targets.
+	// If it is not set, all keys are copied as they are.
+	// +optional
+	Data *DataSpec `json:"data,omitempty"`
+
+	// template renders additional keys of the copies from the source.
+	// +optional
+	Template *TemplateSpec `json:"template,omitempty"`
+
+	// metadata decides which labels and annotations, besides the ones the controller needs
+	// to track the copies, are set on the copies.
+	// +optional
+	Metadata *MetadataSpec `json:"metadata,omitempty"`
+}
+
+// SourceRef is one of the sources that are merged into a single copy.
+type SourceRef struct {
+	// name is the name of the source object, it is of the kind set in sourceKind.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// namespace is the namespace of the source object, it defaults to sourceNamespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// include lists the keys of this source that are merged, entries may be glob patterns such as *.crt.
+	// If it is empty, all keys are merged.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// exclude lists the keys of this source that are not merged, entries may be glob patterns.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// prefix is prepended to every key of this source, for example db_ turns password into db_password.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// Kind returns the kind of the source and of its copies, Secret if sourceKind is not set.
+func (s *SecretSyncSpec) Kind() SourceKind {
+	if s.SourceKind == "" {
+		return SourceKindSecret
+	}
+	return s.SourceKind
+}
+
+// SourceNamespaceFor returns the namespace of a source of the merged sources.
+func (s *SecretSyncSpec) SourceNamespaceFor(ref SourceRef) string {
+	if ref.Namespace != "" {
+		return ref.Namespace
+	}
+	return s.SourceNamespace
+}
+
+// IsSourceNamespace returns true if a source is read from the namespace, it is either sourceNamespace
+// or the namespace of an entry of sources. A source namespace is never a target, a copy with the name
+// of the source would overwrite the source itself.
+func (s *SecretSyncSpec) IsSourceNamespace(namespace string) bool {
+	return namespace == s.SourceNamespace || slices.ContainsFunc(s.Sources, func(ref SourceRef) bool {
+		return ref.Namespace == namespace
+	})
+}
+
+// TargetNameFor returns the name of the copy in the target namespace, the per namespace
+// override wins over targetName, which wins over the name of the source.
+func (s *SecretSyncSpec) TargetNameFor(namespace string) string {
+	if name := s.TargetNameOverrides[namespace]; name != "" {
+		return name
+	}
+	if s.TargetName != "" {
+		return s.TargetName
+	}
+	return s.SourceName
+}
+
+// MetadataSpec describes the labels and annotations of the copies.
+type MetadataSpec struct {
+	// labels decides which labels are set on the copies.
+	// +optional
+	Labels *MetadataRules `json:"labels,omitempty"`
+
+	// annotations decides which annotations are set on the copies.
+	// +optional
+	Annotations *MetadataRules `json:"annotations,omitempty"`
 }
 
-// SecretSyncStatus defines the observed state of SecretSync.
+// MetadataRules selects labels or annotations of the source and adds static ones.
+// The keys the controller uses to track the copies, app.kubernetes.io/managed-by and
+// every key with the secretsync.example.com/ prefix, are never copied or overwritten.
+type MetadataRules struct {
+	// keys lists the keys that are copied from the source.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// prefixes lists prefixes, every key of the source that starts with one of them is copied.
+	// +optional
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// static is set on every copy, it wins over a key copied from the source.
+	// +optional
+	Static map[string]string `json:"static,omitempty"`
+}
+
+// TemplateSpec renders keys of the copies with Go text/template.
+type TemplateSpec struct {
+	// data maps a key of the copies to a Go text/template that renders its value.
+	// The template is executed against .Data, the data of the source as strings, and .Metadata,
+	// the name, namespace, labels and annotations of the source.
+	// The helpers b64enc, b64dec, toJson, trim, trimPrefix, trimSuffix, upper, lower, replace,
+	// default and quote are available. Rendered keys replace the keys of the source with the same name.
+	// +optional
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// DataSpec selects and renames the keys that are copied from the source.
+// Keys are first filtered with include and exclude, the keys that are left are then renamed.
+type DataSpec struct {
+	// include lists the keys that are copied, entries may be glob patterns such as *.crt.
+	// If it is empty, all keys are included.
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// exclude lists the keys that are not copied, entries may be glob patterns such as *.key.
+	// A key that matches both include and exclude is not copied.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+
+	// rename maps a key of the source to the key it is written to in the copies,
+	// for example ca.crt: ca-bundle.crt. Keys that are not listed keep their name.
+	// +optional
+	Rename map[string]string `json:"rename,omitempty"`
+}
+
+// SourceKind is the kind of object that is synced.
+// +kubebuilder:validation:Enum=Secret;ConfigMap
+type SourceKind string
+
+const (
+	// SourceKindSecret syncs a Secret.
+	SourceKindSecret SourceKind = "Secret"
+	// SourceKindConfigMap syncs a ConfigMap.
+	SourceKindConfigMap SourceKind = "ConfigMap"
+)
+
+// PrunePolicy describes what happens to a copy that is no longer wanted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type PrunePolicy string
+
+const (
+	// PrunePolicyDelete deletes copies that are no longer wanted.
+	PrunePolicyDelete PrunePolicy = "Delete"
+	// PrunePolicyOrphan leaves copies that are no longer wanted in place but stops managing them.
+	PrunePolicyOrphan PrunePolicy = "Orphan"
+)
+
+// RolloutTarget is a kind of workload that can be restarted when a copy changes.
+// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+type RolloutTarget string
+
+const (
+	// RolloutTargetDeployment restarts Deployments.
+	RolloutTargetDeployment RolloutTarget = "Deployment"
+	// RolloutTargetStatefulSet restarts StatefulSets.
+	RolloutTargetStatefulSet RolloutTarget = "StatefulSet"
+	// RolloutTargetDaemonSet restarts DaemonSets.
+	RolloutTargetDaemonSet RolloutTarget = "DaemonSet"
+)
+
+// DeletionPolicy describes what happens to the copies when their SecretSync is deleted.
+// +kubebuilder:validation:Enum=Delete;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the copies together with the SecretSync.
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// DeletionPolicyOrphan leaves the copies in place but stops managing them.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// ConflictPolicy describes what happens to an existing object that is not managed by the controller.
+// +kubebuilder:validation:Enum=Fail;Adopt;Overwrite
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyFail leaves existing objects alone and fails the sync into their namespace.
+	ConflictPolicyFail ConflictPolicy = "Fail"
+	// ConflictPolicyAdopt takes over existing objects whose data matches the source.
+	ConflictPolicyAdopt ConflictPolicy = "Adopt"
+	// ConflictPolicyOverwrite takes over existing objects and replaces their data.
+	ConflictPolicyOverwrite ConflictPolicy = "Overwrite"
+)
+
+// ConflictResolution records how an existing object was taken over.
+// +kubebuilder:validation:Enum=Adopted;Overwritten
+type ConflictResolution string
+
+const (
+	// ConflictResolutionAdopted means an existing object with matching data was adopted.
+	ConflictResolutionAdopted ConflictResolution = "Adopted"
+	// ConflictResolutionOverwritten means an existing object was taken over and its data replaced.
+	ConflictResolutionOverwritten ConflictResolution = "Overwritten"
+)
+
+// SecretSyncStatus defines the observed state of SecretSync and ClusterSecretSync.
 type SecretSyncStatus struct {
 	// lastSyncTime is the last time the sync operation was performed.
 	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
 	// conditions is a list of conditions that describe the current state of the SecretSync CR.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// sourceHash is the hash of the data and type the copies should hold, every copy
+	// that is in sync carries the same hash in its secretsync.example.com/source-hash annotation.
+	// +optional
+	SourceHash string `json:"sourceHash,omitempty"`
+	// keySources records, for a SecretSync with several sources, which source every key of the copies
+	// comes from, as <namespace>/<name>, keys rendered by spec.template are reported as spec.template.
+	// +optional
+	KeySources map[string]string `json:"keySources,omitempty"`
+	// lastHandledReconcileAt is the last value of the secretsync.example.com/reconcile-at annotation
+	// that was handled, every copy was re-applied for it.
+	// +optional
+	LastHandledReconcileAt string `json:"lastHandledReconcileAt,omitempty"`
+	// prunedTargets lists the copies, as <namespace>/<name>, that were pruned during the last sync
+	// because their namespace is no longer a target.
+	// +optional
+	PrunedTargets []string `json:"prunedTargets,omitempty"`
+	// targets records the result of the last sync into each target namespace.
+	// +listType=map
+	// +listMapKey=namespace
+	// +optional
+	Targets []TargetStatus `json:"targets,omitempty"`
+}
+
+// TargetResult is the result of the last sync into a single target namespace.
+// +kubebuilder:validation:Enum=Synced;Failed;Denied;Conflict
+type TargetResult string
+
+const (
+	// TargetResultSynced means the copy in the target namespace is up to date.
+	TargetResultSynced TargetResult = "Synced"
+	// TargetResultFailed means the copy in the target namespace could not be written.
+	TargetResultFailed TargetResult = "Failed"
+	// TargetResultDenied means the copy is not allowed in the target namespace, for example
+	// because the owner of the source does not allow it to be exported to the namespace.
+	TargetResultDenied TargetResult = "Denied"
+	// TargetResultConflict means another SecretSync or ClusterSecretSync writes the same copy and was
+	// created first, the copy is left to that instance.
+	TargetResultConflict TargetResult = "Conflict"
+)
+
+// TargetStatus describes the state of the copy in a single target namespace.
+type TargetStatus struct {
+	// namespace is the target namespace.
+	Namespace string `json:"namespace"`
+	// name is the name of the copy in the target namespace.
+	Name string `json:"name"`
+	// result is the result of the last sync into this namespace.
+	Result TargetResult `json:"result"`
+	// lastSyncTime is the last time the copy was written successfully.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// sourceResourceVersion is the resourceVersion of the source that was last written successfully.
+	// +optional
+	SourceResourceVersion string `json:"sourceResourceVersion,omitempty"`
+	// sourceHash is the hash of the data and type that was last written successfully.
+	// +optional
+	SourceHash string `json:"sourceHash,omitempty"`
+	// conflictResolution records how the copy was taken over, if an object that was not managed
+	// by the controller already existed in the namespace.
+	// +optional
+	ConflictResolution ConflictResolution `json:"conflictResolution,omitempty"`
+	// lastError is the error of the last sync into this namespace, it is cleared once a sync succeeds.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -51,6 +405,7 @@ type SecretSyncStatus struct {
 // +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="Message",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].message"
 // +kubebuilder:printcolumn:name="LastTransition",type=string,JSONPath=".status.conditions[?(@.type=='Synced')].lastTransitionTime"
+// +kubebuilder:printcolumn:name="Suspended",type=boolean,JSONPath=".spec.suspend"
 //
 // SecretSync is the Schema for the secretsyncs API.
 type SecretSync struct {
@@ -61,6 +416,16 @@ type SecretSync struct {
 	Status SecretSyncStatus `json:"status,omitempty"`
 }
 
+// GetSpec returns the spec of the SecretSync.
+func (s *SecretSync) GetSpec() *SecretSyncSpec {
+	return &s.Spec
+}
+
+// GetStatus returns the status of the SecretSync.
+func (s *SecretSync) GetStatus() *SecretSyncStatus {
+	return &s.Status
+}
+
 // +kubebuilder:object:root=true
 
 // SecretSyncList contains a list of SecretSync.
@@ -21,6 +21,7 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -39,6 +40,7 @@ import (
 
 	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
 	"github.com/prit342/secret-sync-controller/internal/controller"
+	webhooksyncv1alpha1 "github.com/prit342/secret-sync-controller/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -63,6 +65,7 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var retryBaseDelay, retryMaxDelay time.Duration
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -81,6 +84,11 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&retryBaseDelay, "retry-base-delay", controller.DefaultRetryBaseDelay,
+		"The delay before a failed sync is retried, it doubles on every consecutive failure.")
+	flag.DurationVar(&retryMaxDelay, "retry-max-delay", controller.DefaultRetryMaxDelay,
+		"The maximum delay between retries of a failed sync. Errors that need a change by the user, "+
+			"such as an invalid spec or a conflicting secret, are retried after this delay.")
 	opts := zap.Options{
 		Development: false,
 	}
@@ -202,13 +210,33 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := (&controller.SecretSyncReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	secretSyncReconciler := &controller.SecretSyncReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("secret-sync-controller"),
+		APIReader: mgr.GetAPIReader(),
+
+		RetryBaseDelay: retryBaseDelay,
+		RetryMaxDelay:  retryMaxDelay,
+	}
+	if err := secretSyncReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SecretSync")
 		os.Exit(1)
 	}
+	// ClusterSecretSync shares the sync logic and the settings of the SecretSync reconciler
+	if err := (&controller.ClusterSecretSyncReconciler{
+		SecretSyncReconciler: secretSyncReconciler,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterSecretSync")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhooksyncv1alpha1.SetupSecretSyncWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SecretSync")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
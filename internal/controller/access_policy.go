@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// exportToAnnotation is a comma separated list of namespaces, or glob patterns such as team-*,
+	// that the source may be copied to, "*" allows every namespace. Setting it, or the
+	// export-to-selector annotation, also lets namespaced SecretSyncs in other namespaces read the source
+	exportToAnnotation = "secretsync.example.com/export-to"
+	// exportToSelectorAnnotation is a label selector, such as tenant=true, matched against
+	// the labels of the namespaces the source may be copied to
+	exportToSelectorAnnotation = "secretsync.example.com/export-to-selector"
+	// acceptFromAnnotation is set on a target namespace by its owner, it is a comma separated list
+	// of who may write into the namespace, each entry is either
+	//   - a source namespace, or a glob pattern such as team-*
+	//   - <namespace>/<name> of a SecretSync
+	//   - ClusterSecretSync/<name> of a ClusterSecretSync
+	acceptFromAnnotation = "secretsync.example.com/accept-from"
+	// clusterSecretSyncPrefix is the prefix of accept-from entries that name a ClusterSecretSync
+	clusterSecretSyncPrefix = "ClusterSecretSync/"
+)
+
+// targetDenial records why a target namespace may not receive a copy
+type targetDenial struct {
+	reason  string // the reason reported on the Synced condition
+	message string // a human readable explanation
+}
+
+// isClusterScoped - returns true if the instance is a ClusterSecretSync
+func isClusterScoped(instance syncInstance) bool {
+	return instance.GetNamespace() == ""
+}
+
+// checkSourceExported - makes sure a namespaced SecretSync only reads a source from its own namespace,
+// unless the source explicitly opted in to being exported by listing where it may be copied to.
+// Without this check anyone who can create a SecretSync in their own namespace could copy
+// any secret of the cluster into a namespace they can read.
+// Every source of a merged source must be exported.
+func checkSourceExported(instance syncInstance, src *sourceObject) error {
+	if isClusterScoped(instance) {
+		return nil
+	}
+	for _, part := range src.objects() {
+		if part.GetNamespace() == instance.GetNamespace() {
+			continue
+		}
+		annotations := part.GetAnnotations()
+		if _, ok := annotations[exportToAnnotation]; ok {
+			continue
+		}
+		if _, ok := annotations[exportToSelectorAnnotation]; ok {
+			continue
+		}
+		return fmt.Errorf("the source %s %s in namespace %s is not exported, a SecretSync can only read a source "+
+			"from another namespace if the source lists the allowed namespaces in the annotation %s, \"*\" allows every namespace",
+			sourceKindOf(instance), part.GetName(), part.GetNamespace(), exportToAnnotation)
+	}
+	return nil
+}
+
+// exportPolicy is the set of namespaces the owner of a source allows the source to be copied to
+type exportPolicy struct {
+	patterns []string        // namespace names or glob patterns from the export-to annotation
+	selector labels.Selector // namespace label selector from the export-to-selector annotation, may be nil
+}
+
+// parseExportPolicy - reads the export policy from the annotations of the source
+// a source without export annotations is not exported, its policy allows no namespace
+func parseExportPolicy(src *sourceObject) (*exportPolicy, error) {
+	annotations := src.GetAnnotations()
+	exportTo := annotations[exportToAnnotation]
+	exportToSelector, hasSelector := annotations[exportToSelectorAnnotation]
+
+	policy := &exportPolicy{}
+	for _, pattern := range strings.Split(exportTo, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, permanent(fmt.Errorf("invalid pattern %q in annotation %s on %s %s/%s: %w",
+				pattern, exportToAnnotation, src.kind, src.GetNamespace(), src.GetName(), err))
+		}
+		policy.patterns = append(policy.patterns, pattern)
+	}
+	if hasSelector {
+		selector, err := labels.Parse(exportToSelector)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("invalid selector in annotation %s on %s %s/%s: %w",
+				exportToSelectorAnnotation, src.kind, src.GetNamespace(), src.GetName(), err))
+		}
+		policy.selector = selector
+	}
+	return policy, nil
+}
+
+// allowsName - returns true if the namespace name matches one of the export-to patterns
+func (p *exportPolicy) allowsName(ns string) bool {
+	return slices.ContainsFunc(p.patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, ns) // the patterns were validated when they were parsed
+		return ok
+	})
+}
+
+// checkExportTargets - checks every target namespace against the export policy of the source,
+// the namespaces the source owner has not allowed are returned with the reason they were denied
+// a merged source may only be copied to the namespaces every one of its sources allows
+func (r *SecretSyncReconciler) checkExportTargets(
+	ctx context.Context, // context for the API call
+	src *sourceObject, // the source that is copied
+	targetNamespaces []string, // the namespaces the instance wants to copy the source to
+) (map[string]targetDenial, error) {
+	denied := make(map[string]targetDenial)
+	for _, part := range src.objects() {
+		if err := r.checkExportPolicy(ctx, part, targetNamespaces, denied); err != nil {
+			return nil, err
+		}
+	}
+	return denied, nil
+}
+
+// checkExportPolicy - adds the target namespaces the export policy of a single source does not allow to denied
+func (r *SecretSyncReconciler) checkExportPolicy(
+	ctx context.Context, // context for the API call
+	src *sourceObject, // a single source, not a merged one
+	targetNamespaces []string, // the namespaces the instance wants to copy the source to
+	denied map[string]targetDenial, // the namespaces that are denied so far
+) error {
+	policy, err := parseExportPolicy(src)
+	if err != nil {
+		return err
+	}
+
+	for _, ns := range targetNamespaces {
+		if _, ok := denied[ns]; ok || policy.allowsName(ns) {
+			continue
+		}
+		if policy.selector != nil {
+			var namespace corev1.Namespace
+			err := r.Get(ctx, types.NamespacedName{Name: ns}, &namespace)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("error reading namespace %s: %w", ns, err)
+			}
+			// a namespace that does not exist has no labels and cannot match the selector
+			if err == nil && policy.selector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+		denied[ns] = targetDenial{
+			reason: exportNotAllowedReason,
+			message: fmt.Sprintf("the owner of %s %s/%s does not allow it to be copied to namespace %s, "+
+				"the namespaces it may be copied to are listed in the annotations %s and %s",
+				src.kind, src.GetNamespace(), src.GetName(), ns, exportToAnnotation, exportToSelectorAnnotation),
+		}
+	}
+	return nil
+}
+
+// denyTargets - reports every target of the last sync as Denied with the message, used when
+// the whole source may not be copied anymore and every copy was pruned
+func denyTargets(instance syncInstance, message string) {
+	targets := instance.GetStatus().Targets
+	for i := range targets {
+		targets[i].Result = syncv1alpha1.TargetResultDenied
+		targets[i].LastError = message
+	}
+	recordTargetMetrics(instance, targets)
+}
+
+// deniedNamespaces - returns the sorted namespaces that are denied
+func deniedNamespaces(denied map[string]targetDenial) []string {
+	namespaces := make([]string, 0, len(denied))
+	for ns := range denied {
+		namespaces = append(namespaces, ns)
+	}
+	slices.Sort(namespaces)
+	return namespaces
+}
+
+// allowedNamespaces - returns the target namespaces that are not denied
+func allowedNamespaces(targetNamespaces []string, denied map[string]targetDenial) []string {
+	return slices.DeleteFunc(slices.Clone(targetNamespaces), func(ns string) bool {
+		_, ok := denied[ns]
+		return ok
+	})
+}
+
+// checkImportConsent - checks the accept-from annotation of the target namespace, a namespace
+// without the annotation accepts copies from everyone
+// it returns nil if the instance may write into the namespace
+func (r *SecretSyncReconciler) checkImportConsent(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that wants to write into the namespace
+	src *sourceObject, // the source that is copied
+	ns string, // the target namespace
+) (*targetDenial, error) {
+	var namespace corev1.Namespace
+	if err := r.Get(ctx, types.NamespacedName{Name: ns}, &namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil // writing into the namespace will fail and report the missing namespace
+		}
+		return nil, fmt.Errorf("error reading namespace %s: %w", ns, err)
+	}
+
+	acceptFrom, ok := namespace.Annotations[acceptFromAnnotation]
+	if !ok {
+		return nil, nil // the owner of the namespace does not restrict who can write into it
+	}
+	if acceptsFrom(acceptFrom, instance, src) {
+		return nil, nil
+	}
+	return &targetDenial{
+		reason: importNotAcceptedReason,
+		message: fmt.Sprintf("namespace %s does not accept copies from %s (%s: %s)",
+			ns, instanceRef(instance), acceptFromAnnotation, acceptFrom),
+	}, nil
+}
+
+// acceptsFrom - returns true if one of the entries of the accept-from annotation matches
+// the namespace of the source or the instance that copies it
+// a merged source is only accepted by namespace if the namespaces of all its sources match
+func acceptsFrom(acceptFrom string, instance syncInstance, src *sourceObject) bool {
+	ref := instanceRef(instance)
+	var patterns []string
+	for _, entry := range strings.Split(acceptFrom, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			// the entry names a SecretSync or a ClusterSecretSync
+			if entry == ref {
+				return true
+			}
+		default:
+			// the entry is a source namespace
+			patterns = append(patterns, entry)
+		}
+	}
+	for _, part := range src.objects() {
+		if !slices.ContainsFunc(patterns, func(pattern string) bool {
+			ok, _ := path.Match(pattern, part.GetNamespace()) // invalid patterns never match
+			return ok
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// instanceRef - returns how the instance is referenced in the accept-from annotation,
+// <namespace>/<name> for a SecretSync and ClusterSecretSync/<name> for a ClusterSecretSync
+func instanceRef(instance syncInstance) string {
+	if isClusterScoped(instance) {
+		return clusterSecretSyncPrefix + instance.GetName()
+	}
+	return instance.GetNamespace() + "/" + instance.GetName()
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("exportPolicy", func() {
+	newSource := func(annotations map[string]string) *sourceObject {
+		return newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-secret",
+			Namespace:   "default",
+			Annotations: annotations,
+		}})
+	}
+
+	It("should not allow any namespace for a source without export annotations", func() {
+		policy, err := parseExportPolicy(newSource(nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.allowsName("team-a")).To(BeFalse())
+		Expect(policy.selector).To(BeNil())
+	})
+
+	It("should allow the listed namespaces and glob patterns only", func() {
+		policy, err := parseExportPolicy(newSource(map[string]string{exportToAnnotation: "team-a, tenant-*"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.allowsName("team-a")).To(BeTrue())
+		Expect(policy.allowsName("tenant-42")).To(BeTrue())
+		Expect(policy.allowsName("team-b")).To(BeFalse())
+	})
+
+	It("should allow every namespace with the * pattern", func() {
+		policy, err := parseExportPolicy(newSource(map[string]string{exportToAnnotation: "*"}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.allowsName("team-a")).To(BeTrue())
+		Expect(policy.allowsName("kube-system")).To(BeTrue())
+	})
+
+	It("should reject invalid patterns and selectors", func() {
+		_, err := parseExportPolicy(newSource(map[string]string{exportToAnnotation: "team-["}))
+		Expect(err).To(HaveOccurred())
+		_, err = parseExportPolicy(newSource(map[string]string{exportToSelectorAnnotation: "tenant in ("}))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("checkExportTargets", func() {
+	ctx := context.Background()
+	newSource := func(annotations map[string]string) *sourceObject {
+		return newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-secret",
+			Namespace:   "default",
+			Annotations: annotations,
+		}})
+	}
+
+	It("should deny every target of a source without export annotations", func() {
+		denied, err := newTestReconciler().checkExportTargets(ctx, newSource(nil), []string{"team-a", "team-b"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deniedNamespaces(denied)).To(Equal([]string{"team-a", "team-b"}))
+		Expect(denied["team-a"].reason).To(Equal(exportNotAllowedReason))
+	})
+
+	It("should only deny the targets the export policy does not allow", func() {
+		r := newTestReconciler(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant", Labels: map[string]string{"tenant": "true"},
+		}})
+		denied, err := r.checkExportTargets(ctx, newSource(map[string]string{
+			exportToAnnotation:         "team-a",
+			exportToSelectorAnnotation: "tenant=true",
+		}), []string{"team-a", "team-b", "tenant"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deniedNamespaces(denied)).To(Equal([]string{"team-b"}))
+	})
+})
+
+var _ = Describe("checkSourceExported", func() {
+	instance := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{Name: "sync-db", Namespace: "team-a"}}
+	newSource := func(namespace string, annotations map[string]string) *sourceObject {
+		return newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "my-secret",
+			Namespace:   namespace,
+			Annotations: annotations,
+		}})
+	}
+
+	It("should allow a source in the namespace of the SecretSync", func() {
+		Expect(checkSourceExported(instance, newSource("team-a", nil))).To(Succeed())
+	})
+
+	It("should only allow a source from another namespace that lists where it may be copied to", func() {
+		Expect(checkSourceExported(instance, newSource("platform", nil))).NotTo(Succeed())
+		Expect(checkSourceExported(instance, newSource("platform", map[string]string{
+			"secretsync.example.com/exportable": "true",
+		}))).NotTo(Succeed())
+		Expect(checkSourceExported(instance, newSource("platform", map[string]string{exportToAnnotation: "*"}))).To(Succeed())
+		Expect(checkSourceExported(instance, newSource("platform", map[string]string{
+			exportToSelectorAnnotation: "tenant=true",
+		}))).To(Succeed())
+	})
+
+	It("should not require a ClusterSecretSync source to be exported", func() {
+		cluster := &syncv1alpha1.ClusterSecretSync{ObjectMeta: metav1.ObjectMeta{Name: "sync-registry"}}
+		Expect(checkSourceExported(cluster, newSource("platform", nil))).To(Succeed())
+	})
+})
+
+var _ = Describe("acceptsFrom", func() {
+	src := newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "platform"}})
+	namespaced := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{Name: "sync-db", Namespace: "team-a"}}
+	cluster := &syncv1alpha1.ClusterSecretSync{ObjectMeta: metav1.ObjectMeta{Name: "sync-registry"}}
+
+	It("should accept copies from the listed source namespaces", func() {
+		Expect(acceptsFrom("team-b, plat*", namespaced, src)).To(BeTrue())
+		Expect(acceptsFrom("team-b", namespaced, src)).To(BeFalse())
+	})
+
+	It("should accept copies from the listed SecretSyncs and ClusterSecretSyncs", func() {
+		Expect(acceptsFrom("team-a/sync-db", namespaced, src)).To(BeTrue())
+		Expect(acceptsFrom("team-a/other", namespaced, src)).To(BeFalse())
+		Expect(acceptsFrom("ClusterSecretSync/sync-registry", cluster, src)).To(BeTrue())
+		Expect(acceptsFrom("ClusterSecretSync/sync-registry", namespaced, src)).To(BeFalse())
+	})
+})
+
+var _ = Describe("denyTargets", func() {
+	It("should report every target of the last sync as Denied", func() {
+		instance := &syncv1alpha1.SecretSync{Status: syncv1alpha1.SecretSyncStatus{Targets: []syncv1alpha1.TargetStatus{
+			{Namespace: "team-a", Result: syncv1alpha1.TargetResultSynced},
+			{Namespace: "team-b", Result: syncv1alpha1.TargetResultFailed, LastError: "boom"},
+		}}}
+		denyTargets(instance, "not exported")
+		for _, target := range instance.Status.Targets {
+			Expect(target.Result).To(Equal(syncv1alpha1.TargetResultDenied))
+			Expect(target.LastError).To(Equal("not exported"))
+		}
+	})
+})
@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterSecretSyncReconciler reconciles a ClusterSecretSync object.
+// ClusterSecretSync shares its spec and status with SecretSync, so the reconciler
+// embeds the SecretSyncReconciler and reuses all of its sync logic.
+type ClusterSecretSyncReconciler struct {
+	*SecretSyncReconciler
+}
+
+// +kubebuilder:rbac:groups=sync.example.com,resources=clustersecretsyncs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sync.example.com,resources=clustersecretsyncs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=sync.example.com,resources=clustersecretsyncs/finalizers,verbs=update
+
+// Reconcile reads the ClusterSecretSync and runs the same reconciliation as for a SecretSync.
+// A ClusterSecretSync is cluster-scoped, so it can read a source from any namespace without
+// the source opting in to being exported.
+func (r *ClusterSecretSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.Log.WithName("ClusterSecretSyncReconciler")
+
+	l.Info("reconciling for", "name", req.Name)
+	// instance is the CR that called the reconcile function
+	instance := &syncv1alpha1.ClusterSecretSync{}
+
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			l.Info("instance not found, it might have been deleted", "name", req.Name)
+			return ctrl.Result{}, nil // No need to requeue, the instance is not present
+		}
+		l.Error(err, "failed to get instance", "name", req.Name)
+		return ctrl.Result{}, err
+	}
+
+	return r.reconcileInstance(ctx, instance, l)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The watches are the same as for SecretSync, see SecretSyncReconciler.SetupWithManager.
+func (r *ClusterSecretSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the bySourceSecret index, this time for ClusterSecretSyncs
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&syncv1alpha1.ClusterSecretSync{},
+		bySourceSecretIndexKey,
+		indexBySource,
+	); err != nil {
+		return err
+	}
+	// the byTargetSecret index, this time for ClusterSecretSyncs
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&syncv1alpha1.ClusterSecretSync{},
+		byTargetSecretIndexKey,
+		indexByTarget,
+	); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&syncv1alpha1.ClusterSecretSync{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToClusterSecretSyncs),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapSecretToClusterSecretSyncs),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToClusterSecretSyncs),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})),
+		).
+		Watches(
+			&syncv1alpha1.SecretSync{},
+			handler.EnqueueRequestsFromMapFunc(r.mapOverlappingToClusterSecretSyncs),
+			builder.WithPredicates(overlapChangedPredicate),
+		).
+		Watches(
+			&syncv1alpha1.ClusterSecretSync{},
+			handler.EnqueueRequestsFromMapFunc(r.mapOverlappingToClusterSecretSyncs),
+			builder.WithPredicates(overlapChangedPredicate),
+		).
+		Named("clustersecretsync").
+		// failed reconciles are retried with exponential backoff between RetryBaseDelay and RetryMaxDelay
+		WithOptions(controller.Options{RateLimiter: r.rateLimiter()}).
+		Complete(r)
+}
+
+// mapSecretToClusterSecretSyncs maps a Secret or ConfigMap event to the ClusterSecretSyncs
+// that use the changed object as their source.
+func (r *ClusterSecretSyncReconciler) mapSecretToClusterSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapSourceToSyncs(ctx, &syncv1alpha1.ClusterSecretSyncList{}, obj)
+}
+
+// mapOverlappingToClusterSecretSyncs maps a SecretSync or ClusterSecretSync event to the ClusterSecretSyncs
+// that write one of its copies.
+func (r *ClusterSecretSyncReconciler) mapOverlappingToClusterSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapOverlappingToSyncs(ctx, &syncv1alpha1.ClusterSecretSyncList{}, obj)
+}
+
+// mapNamespaceToClusterSecretSyncs maps a Namespace event to the ClusterSecretSyncs
+// that may need to sync into, or prune from, the namespace.
+func (r *ClusterSecretSyncReconciler) mapNamespaceToClusterSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapNamespaceToSyncs(ctx, &syncv1alpha1.ClusterSecretSyncList{}, obj)
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// desiredData - returns the data that is written to the copies, the keys selected by spec.data
+// and the keys rendered from spec.template, a rendered key replaces a selected key with the same name
+func desiredData(instance syncInstance, src *sourceObject) (map[string][]byte, error) {
+	selected, err := selectData(src.data, instance.GetSpec().Data)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := renderTemplate(instance.GetSpec().Template, src)
+	if err != nil {
+		return nil, err
+	}
+	if len(rendered) == 0 {
+		return selected, nil
+	}
+	data := maps.Clone(selected)
+	if data == nil {
+		data = make(map[string][]byte, len(rendered))
+	}
+	maps.Copy(data, rendered)
+	return data, nil
+}
+
+// selectData - returns the data that is written to the copies, only the keys selected by the
+// include and exclude lists of spec are kept and they are renamed according to the rename map
+// the source data is not modified
+func selectData(
+	data map[string][]byte, // the data of the source
+	spec *syncv1alpha1.DataSpec, // spec.data of the instance, may be nil
+) (map[string][]byte, error) {
+	if spec == nil {
+		return data, nil // everything is copied as it is
+	}
+	for _, pattern := range slices.Concat(spec.Include, spec.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q in spec.data: %w", pattern, err)
+		}
+	}
+
+	selected := make(map[string][]byte, len(data))
+	renamedFrom := make(map[string]string, len(data)) // the source key of every key we write
+	for key, value := range data {
+		if len(spec.Include) > 0 && !matchesAny(spec.Include, key) {
+			continue
+		}
+		if matchesAny(spec.Exclude, key) {
+			continue
+		}
+		newKey := key
+		if renamed, ok := spec.Rename[key]; ok {
+			newKey = renamed
+		}
+		// two source keys must never end up in the same key of the copy,
+		// otherwise the result would depend on the order of the map iteration
+		if other, ok := renamedFrom[newKey]; ok {
+			first, second := min(key, other), max(key, other)
+			return nil, fmt.Errorf("keys %q and %q of the source are both written to key %q, check spec.data.rename",
+				first, second, newKey)
+		}
+		renamedFrom[newKey] = key
+		selected[newKey] = value
+	}
+	return selected, nil
+}
+
+// matchesAny - returns true if key matches one of the glob patterns
+func matchesAny(patterns []string, key string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, key) // the patterns were validated by the caller
+		return ok
+	})
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("selectData", func() {
+	data := map[string][]byte{
+		"tls.crt": []byte("cert"),
+		"tls.key": []byte("key"),
+		"ca.crt":  []byte("ca"),
+	}
+
+	It("should copy everything without spec.data", func() {
+		Expect(selectData(data, nil)).To(Equal(data))
+	})
+
+	It("should filter and rename the keys", func() {
+		selected, err := selectData(data, &syncv1alpha1.DataSpec{
+			Include: []string{"*.crt", "tls.*"},
+			Exclude: []string{"tls.key"},
+			Rename:  map[string]string{"ca.crt": "ca-bundle.crt"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).To(Equal(map[string][]byte{
+			"tls.crt":       []byte("cert"),
+			"ca-bundle.crt": []byte("ca"),
+		}))
+	})
+
+	It("should reject renames that collide and invalid patterns", func() {
+		_, err := selectData(data, &syncv1alpha1.DataSpec{Rename: map[string]string{"ca.crt": "tls.crt"}})
+		Expect(err).To(MatchError(ContainSubstring(`"ca.crt" and "tls.crt"`)))
+		_, err = selectData(data, &syncv1alpha1.DataSpec{Include: []string{"tls.["}})
+		Expect(err).To(HaveOccurred())
+	})
+})
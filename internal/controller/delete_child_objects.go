@@ -4,35 +4,163 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // deleteChildObjects - deletes the child objects that belongs to the instance i.e  CR
+// with spec.deletionPolicy Orphan the child objects are released instead and left in place
+// it returns the deleted or released child objects as <namespace>/<name>
 func (r *SecretSyncReconciler) deleteChildObjects(
 	ctx context.Context, // context for the API call
-	instance *syncv1alpha1.SecretSync, // the CR that should create the secrets
-) error {
+	instance syncInstance, // the CR that should create the secrets
+) ([]string, error) {
+	policy := syncv1alpha1.PrunePolicyDelete
+	if instance.GetSpec().DeletionPolicy == syncv1alpha1.DeletionPolicyOrphan {
+		policy = syncv1alpha1.PrunePolicyOrphan
+	}
+	// the copies are discovered through our ownership labels and not through the spec,
+	// this way we also delete copies in namespaces that were removed from the spec
+	// or that were matched by the targetNamespaceSelector
+	// passing no target namespaces means that none of the copies are wanted anymore
+	return r.pruneCopies(ctx, instance, nil, nil, policy)
+}
+
+// listChildObjects - returns all the copies that are owned by the instance, in any namespace
+// both Secrets and ConfigMaps are returned, so that copies of the old kind are found
+// after the sourceKind of the instance has been changed
+func (r *SecretSyncReconciler) listChildObjects(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that owns the copies
+) ([]client.Object, error) {
+	// we find the copies through the uid label we stamp on every object we create
+	children, err := r.listObjects(ctx, instance, client.MatchingLabels(ownershipLabels(instance)))
+	if err != nil {
+		return nil, err
+	}
+	// copies written before the uid label existed are found by the name and namespace of their owner
+	legacy, err := r.listObjects(ctx, instance, client.MatchingLabels(legacyOwnershipLabels(instance)))
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range legacy {
+		if _, ok := child.GetLabels()[controllerOwnerUIDKey]; !ok {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// listObjects - returns the Secrets and ConfigMaps in any namespace that match the labels
+func (r *SecretSyncReconciler) listObjects(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that owns the objects, used in errors
+	matchingLabels client.MatchingLabels, // the labels the objects must have
+) ([]client.Object, error) {
+	var secretList corev1.SecretList
+	if err := r.List(ctx, &secretList, matchingLabels); err != nil {
+		return nil, fmt.Errorf("error listing secrets owned by %s/%s: %w", instance.GetNamespace(), instance.GetName(), err)
+	}
+	var configMapList corev1.ConfigMapList
+	if err := r.List(ctx, &configMapList, matchingLabels); err != nil {
+		return nil, fmt.Errorf("error listing configmaps owned by %s/%s: %w", instance.GetNamespace(), instance.GetName(), err)
+	}
+
+	objects := make([]client.Object, 0, len(secretList.Items)+len(configMapList.Items))
+	for i := range secretList.Items {
+		objects = append(objects, &secretList.Items[i])
+	}
+	for i := range configMapList.Items {
+		objects = append(objects, &configMapList.Items[i])
+	}
+	return objects, nil
+}
+
+// pruneCopies - deletes or orphans the copies owned by the instance that live in a namespace which
+// is not part of targetNamespaces, for example because the namespace was removed from the spec or
+// stopped matching the targetNamespaceSelector
+// copies whose name is not the resolved target name anymore, because targetName was changed, are pruned as well
+// copies in a denied namespace are always deleted, the owner of the source or of the namespace refused them,
+// the policy only applies to the namespaces that stopped being a target
+// it returns the pruned copies as <namespace>/<name>
+func (r *SecretSyncReconciler) pruneCopies(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that owns the copies
+	targetNamespaces []string, // the namespaces the instance currently syncs into, these copies are kept
+	deniedNamespaces []string, // the namespaces the instance may not sync into, these copies are deleted
+	policy syncv1alpha1.PrunePolicy, // what to do with the other copies that are no longer wanted
+) ([]string, error) {
+
+	children, err := r.listChildObjects(ctx, instance)
+	if err != nil {
+		return nil, err
+	}
 
-	var combineErr error
-	for _, ns := range instance.Spec.TargetNamespaces {
-		// delete the secret in the target namespace
-		err := r.Delete(ctx, &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      instance.Spec.SourceName,
-				Namespace: ns,
-			},
-		})
-
-		// we ignore the not found error, if the object does not exist it means we don't need to delete it
-		// we just want to delete the object if it exists
-		if err != nil && client.IgnoreNotFound(err) != nil {
-			combineErr = errors.Join(combineErr, fmt.Errorf("error deleting secret %s in namespace %s: %w",
-				instance.Spec.SourceName, ns, err))
+	var (
+		kind       = sourceKindOf(instance)
+		pruned     []string
+		combineErr error
+	)
+	for _, child := range children {
+		// a copy is still wanted if it lives in a target namespace, is of the kind we sync
+		// and has the name the copy in that namespace should have
+		if kindOfObject(child) == kind && slices.Contains(targetNamespaces, child.GetNamespace()) &&
+			child.GetName() == targetNameFor(instance, child.GetNamespace()) {
+			continue // still a target, nothing to do
+		}
+
+		policy := policy
+		if slices.Contains(deniedNamespaces, child.GetNamespace()) {
+			policy = syncv1alpha1.PrunePolicyDelete
+		}
+		switch policy {
+		case syncv1alpha1.PrunePolicyOrphan:
+			err = r.orphanCopy(ctx, child)
+		default:
+			// we ignore the not found error, if the object does not exist it means we don't need to delete it
+			err = client.IgnoreNotFound(r.Delete(ctx, child))
+		}
+		if err != nil {
+			combineErr = errors.Join(combineErr, fmt.Errorf("error pruning %s %s in namespace %s: %w",
+				kindOfObject(child), child.GetName(), child.GetNamespace(), err))
+			continue
 		}
+		if policy == syncv1alpha1.PrunePolicyOrphan {
+			r.recordTargetEvent(instance, child, corev1.EventTypeNormal, eventReasonOrphaned,
+				"released %s %s in namespace %s, it is no longer managed", kindOfObject(child), child.GetName(), child.GetNamespace())
+		} else {
+			// the copy is gone, so the event is only recorded on the instance
+			r.recordEvent(instance, corev1.EventTypeNormal, eventReasonPruned,
+				"deleted %s %s in namespace %s", kindOfObject(child), child.GetName(), child.GetNamespace())
+		}
+		// a namespace that is still a target keeps its metrics, only the name of the copy changed there
+		if !slices.Contains(targetNamespaces, child.GetNamespace()) {
+			deleteTargetMetrics(instance, child.GetNamespace())
+		}
+		pruned = append(pruned, child.GetNamespace()+"/"+child.GetName())
 	}
-	return combineErr
+	return pruned, combineErr
+}
+
+// orphanCopy - strips our ownership labels and annotations from a copy and leaves it in place,
+// once this is done the controller no longer manages the copy
+func (r *SecretSyncReconciler) orphanCopy(
+	ctx context.Context, // context for the API call
+	secret client.Object, // the copy we stop managing
+) error {
+	patch := client.MergeFrom(secret.DeepCopyObject().(client.Object))
+
+	labels := secret.GetLabels()
+	annotations := secret.GetAnnotations()
+	// every key of the controller is removed, including the ones of copies written by older versions
+	maps.DeleteFunc(labels, func(key, _ string) bool { return isControllerKey(key) })
+	maps.DeleteFunc(annotations, func(key, _ string) bool { return isControllerKey(key) })
+	secret.SetLabels(labels)
+	secret.SetAnnotations(annotations)
+
+	return client.IgnoreNotFound(r.Patch(ctx, secret, patch))
 }
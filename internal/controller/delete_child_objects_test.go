@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// ownedCopy - returns a copy written by instance into namespace ns, with a label of its own
+func ownedCopy(instance syncInstance, ns, name string) *corev1.Secret {
+	labels := ownershipLabels(instance)
+	labels["app"] = "web"
+	annotations := ownershipAnnotations(instance)
+	annotations[sourceHashAnnotation] = "hash"
+	annotations["team"] = "payments"
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels, Annotations: annotations},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+}
+
+// expectReleased - checks that the copy still exists and carries none of the keys of the controller
+func expectReleased(ctx context.Context, c client.Client, ns, name string) {
+	released := &corev1.Secret{}
+	Expect(c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, released)).To(Succeed())
+	Expect(released.Labels).To(Equal(map[string]string{"app": "web"}))
+	Expect(released.Annotations).To(Equal(map[string]string{"team": "payments"}))
+	Expect(released.Data).To(HaveKeyWithValue("password", []byte("secret")))
+}
+
+// expectDeleted - checks that the copy is gone
+func expectDeleted(ctx context.Context, c client.Client, ns, name string) {
+	err := c.Get(ctx, types.NamespacedName{Namespace: ns, Name: name}, &corev1.Secret{})
+	Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected %s/%s to be deleted, got %v", ns, name, err)
+}
+
+var _ = Describe("pruneCopies", func() {
+	ctx := context.Background()
+
+	var instance *syncv1alpha1.SecretSync
+	var other *syncv1alpha1.SecretSync
+	BeforeEach(func() {
+		instance = &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{Name: "sync-db", Namespace: "default", UID: "uid-1"},
+			Spec: syncv1alpha1.SecretSyncSpec{
+				SourceName:       "db",
+				SourceNamespace:  "default",
+				TargetNamespaces: []string{"team-a"},
+			},
+		}
+		other = &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "uid-2"}}
+	})
+
+	It("should delete the copies that are no longer wanted and keep the rest", func() {
+		r := newTestReconciler(
+			ownedCopy(instance, "team-a", "db"),
+			ownedCopy(instance, "team-a", "old-name"),
+			ownedCopy(instance, "team-b", "db"),
+			ownedCopy(other, "team-c", "db"),
+		)
+		pruned, err := r.pruneCopies(ctx, instance, []string{"team-a"}, nil, syncv1alpha1.PrunePolicyDelete)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(ConsistOf("team-a/old-name", "team-b/db"))
+
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-a", Name: "db"}, &corev1.Secret{})).To(Succeed())
+		expectDeleted(ctx, r.Client, "team-a", "old-name")
+		expectDeleted(ctx, r.Client, "team-b", "db")
+		Expect(r.Get(ctx, types.NamespacedName{Namespace: "team-c", Name: "db"}, &corev1.Secret{})).To(Succeed())
+	})
+
+	It("should release the copies with the Orphan policy, except in denied namespaces", func() {
+		r := newTestReconciler(
+			ownedCopy(instance, "team-b", "db"),
+			ownedCopy(instance, "refused", "db"),
+		)
+		pruned, err := r.pruneCopies(ctx, instance, []string{"team-a"}, []string{"refused"}, syncv1alpha1.PrunePolicyOrphan)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(ConsistOf("team-b/db", "refused/db"))
+
+		expectReleased(ctx, r.Client, "team-b", "db")
+		expectDeleted(ctx, r.Client, "refused", "db")
+	})
+
+	It("should report the pruned copies in status.prunedTargets", func() {
+		// the selector matches no namespace, so every copy is stale and nothing needs to be applied
+		instance.Spec.TargetNamespaces = nil
+		instance.Spec.TargetNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}
+		instance.Finalizers = []string{secretSyncFinalizer}
+		r := newTestReconciler(
+			instance,
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"}},
+			ownedCopy(instance, "team-b", "db"),
+		)
+		_, err := r.Reconcile(ctx, requestFor("default", "sync-db"))
+		Expect(err).NotTo(HaveOccurred())
+
+		expectDeleted(ctx, r.Client, "team-b", "db")
+		updated := &syncv1alpha1.SecretSync{}
+		Expect(r.Get(ctx, client.ObjectKeyFromObject(instance), updated)).To(Succeed())
+		Expect(updated.Status.PrunedTargets).To(Equal([]string{"team-b/db"}))
+	})
+})
+
+var _ = Describe("deleteChildObjects", func() {
+	ctx := context.Background()
+
+	It("should keep the copies and release them when the instance is deleted with the Orphan policy", func() {
+		now := metav1.Now()
+		instance := &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "sync-db",
+				Namespace:         "default",
+				UID:               "uid-1",
+				Finalizers:        []string{secretSyncFinalizer},
+				DeletionTimestamp: &now,
+			},
+			Spec: syncv1alpha1.SecretSyncSpec{
+				SourceName:       "db",
+				SourceNamespace:  "default",
+				TargetNamespaces: []string{"team-a"},
+				DeletionPolicy:   syncv1alpha1.DeletionPolicyOrphan,
+			},
+		}
+		// team-b is no longer a target, its copy is released as well
+		r := newTestReconciler(instance, ownedCopy(instance, "team-a", "db"), ownedCopy(instance, "team-b", "db"))
+		_, err := r.Reconcile(ctx, requestFor("default", "sync-db"))
+		Expect(err).NotTo(HaveOccurred())
+
+		expectReleased(ctx, r.Client, "team-a", "db")
+		expectReleased(ctx, r.Client, "team-b", "db")
+		err = r.Get(ctx, client.ObjectKeyFromObject(instance), &syncv1alpha1.SecretSync{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected the instance to be gone once its finalizer is removed")
+	})
+})
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// event reasons recorded by the controller
+const (
+	eventReasonSourceNotFound   = "SourceNotFound"   // the source object does not exist
+	eventReasonSynced           = "Synced"           // a copy was written with new content
+	eventReasonSyncFailed       = "SyncFailed"       // a copy could not be written
+	eventReasonAdopted          = "Adopted"          // an existing object was taken over because its data matches
+	eventReasonOverwritten      = "Overwritten"      // an existing object was taken over and its data replaced
+	eventReasonConflict         = "Conflict"         // an existing object could not be taken over
+	eventReasonDriftCorrected   = "DriftCorrected"   // a copy that was changed or deleted by someone else was restored
+	eventReasonRolloutRestarted = "RolloutRestarted" // a workload that consumes a changed copy was restarted
+	eventReasonPruned           = "Pruned"           // a copy that is no longer wanted was deleted
+	eventReasonOrphaned         = "Orphaned"         // a copy that is no longer wanted was left in place
+	eventReasonCleanedUp        = "CleanedUp"        // the copies were cleaned up before the instance was deleted
+	eventReasonCleanupFailed    = "CleanupFailed"    // the copies could not be cleaned up
+	eventReasonSuspended        = "Suspended"        // the sync was paused with spec.suspend
+	eventReasonResumed          = "Resumed"          // the sync was resumed after spec.suspend was cleared
+)
+
+// recordEvent - records a Kubernetes Event on obj, it does nothing if the reconciler has no recorder
+func (r *SecretSyncReconciler) recordEvent(
+	obj runtime.Object, // the object the event is about
+	eventType string, // corev1.EventTypeNormal or corev1.EventTypeWarning
+	reason string, // one of the event reasons above
+	messageFmt string, // the message, formatted with args
+	args ...any,
+) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
+
+// recordTargetEvent - records the same Event on the instance and on the affected object in the target namespace,
+// so that it shows up in kubectl describe of both
+func (r *SecretSyncReconciler) recordTargetEvent(
+	instance syncInstance, // the CR that owns, or wants to own, the target
+	target runtime.Object, // the object in the target namespace
+	eventType string, // corev1.EventTypeNormal or corev1.EventTypeWarning
+	reason string, // one of the event reasons above
+	messageFmt string, // the message, formatted with args
+	args ...any,
+) {
+	r.recordEvent(instance, eventType, reason, messageFmt, args...)
+	r.recordEvent(target, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// contentHash - returns a deterministic hash of the type and data that is written to a copy.
+// Map iteration order is random in Go, so the keys are sorted before they are hashed.
+func contentHash(secretType corev1.SecretType, data map[string][]byte) string {
+	h := sha256.New()
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	// every field is prefixed with its length, values may contain any byte so a separator
+	// alone would allow two different secrets to produce the same input to the hash
+	_, _ = fmt.Fprintf(h, "%d:%s", len(secretType), secretType)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(h, "%d:%s%d:", len(k), k, len(data[k]))
+		_, _ = h.Write(data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// metadataHash - returns a deterministic hash of the labels and annotations we write to a copy,
+// it lets us tell if the metadata we want is the metadata we wrote last time, including removed keys
+func metadataHash(labels, annotations map[string]string) string {
+	fields := make(map[string][]byte, len(labels)+len(annotations))
+	for k, v := range labels {
+		fields["labels/"+k] = []byte(v)
+	}
+	for k, v := range annotations {
+		fields["annotations/"+k] = []byte(v)
+	}
+	return contentHash("", fields)
+}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("contentHash", func() {
+	It("should not depend on the order of the keys", func() {
+		a := map[string][]byte{"username": []byte("admin"), "password": []byte("secret")}
+		b := map[string][]byte{"password": []byte("secret"), "username": []byte("admin")}
+		Expect(contentHash(corev1.SecretTypeOpaque, a)).To(Equal(contentHash(corev1.SecretTypeOpaque, b)))
+	})
+
+	It("should change when the data or the type changes", func() {
+		data := map[string][]byte{"key": []byte("value")}
+		hash := contentHash(corev1.SecretTypeOpaque, data)
+		Expect(contentHash(corev1.SecretTypeOpaque, map[string][]byte{"key": []byte("other")})).NotTo(Equal(hash))
+		Expect(contentHash(corev1.SecretTypeTLS, data)).NotTo(Equal(hash))
+	})
+
+	It("should not confuse keys and values", func() {
+		a := map[string][]byte{"ab": []byte("c")}
+		b := map[string][]byte{"a": []byte("bc")}
+		Expect(contentHash(corev1.SecretTypeOpaque, a)).NotTo(Equal(contentHash(corev1.SecretTypeOpaque, b)))
+	})
+})
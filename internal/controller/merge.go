@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// templateKeySource is reported in status.keySources for the keys rendered by spec.template
+const templateKeySource = "spec.template"
+
+// getMergedSource - reads every entry of spec.sources from the API server and merges them into one source
+func (r *SecretSyncReconciler) getMergedSource(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that called the reconcile function
+) (*sourceObject, error) {
+	spec := instance.GetSpec()
+	parts := make([]*sourceObject, 0, len(spec.Sources))
+	for i, ref := range spec.Sources {
+		obj := newObjectForKind(sourceKindOf(instance))
+		key := types.NamespacedName{Name: ref.Name, Namespace: spec.SourceNamespaceFor(ref)}
+		if err := r.Get(ctx, key, obj); err != nil {
+			return nil, fmt.Errorf("error reading spec.sources[%d] %s %s: %w", i, sourceKindOf(instance), key, err)
+		}
+		parts = append(parts, newSourceObject(obj))
+	}
+	// a spec that cannot be applied to the sources is not retried hot
+	merged, err := mergeSources(instance, parts)
+	return merged, permanent(err)
+}
+
+// mergeSources - merges the parts, read in spec.sources order, into a single source
+// the keys of every part are filtered and prefixed according to its entry in spec.sources,
+// a key of a later part wins over the same key of an earlier part
+// the merged source carries the labels and annotations of the parts with the same precedence, and its
+// resourceVersion changes whenever the resourceVersion of one of the parts changes
+func mergeSources(instance syncInstance, parts []*sourceObject) (*sourceObject, error) {
+	spec := instance.GetSpec()
+	merged := &sourceObject{
+		kind:       sourceKindOf(instance),
+		data:       make(map[string][]byte),
+		parts:      parts,
+		keySources: make(map[string]string),
+	}
+	// the parts may be of different types, the required keys of a typed secret are not
+	// guaranteed after the merge, so the merged secret is always Opaque
+	if merged.kind == syncv1alpha1.SourceKindSecret {
+		merged.secretType = corev1.SecretTypeOpaque
+	}
+
+	var names, versions, uids []string
+	labels, annotations := map[string]string{}, map[string]string{}
+	var created metav1.Time
+	var managedFields []metav1.ManagedFieldsEntry
+	for i, part := range parts {
+		ref := spec.Sources[i]
+		for _, pattern := range slices.Concat(ref.Include, ref.Exclude) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid key pattern %q in spec.sources[%d]: %w", pattern, i, err)
+			}
+		}
+		for key, value := range part.data {
+			if len(ref.Include) > 0 && !matchesAny(ref.Include, key) {
+				continue
+			}
+			if matchesAny(ref.Exclude, key) {
+				continue
+			}
+			merged.data[ref.Prefix+key] = value
+			merged.keySources[ref.Prefix+key] = part.GetNamespace() + "/" + part.GetName()
+		}
+
+		name := part.GetName()
+		if part.GetNamespace() != spec.SourceNamespace {
+			name = part.GetNamespace() + "/" + name
+		}
+		names = append(names, name)
+		versions = append(versions, part.GetResourceVersion())
+		uids = append(uids, string(part.GetUID()))
+		maps.Copy(labels, part.GetLabels())
+		maps.Copy(annotations, part.GetAnnotations())
+		if part.GetCreationTimestamp().After(created.Time) {
+			created = part.GetCreationTimestamp()
+		}
+		managedFields = append(managedFields, part.GetManagedFields()...)
+	}
+
+	obj := newObjectForKind(merged.kind)
+	obj.SetNamespace(spec.SourceNamespace)
+	obj.SetName(strings.Join(names, "+"))
+	obj.SetResourceVersion(strings.Join(versions, ","))
+	obj.SetUID(types.UID(strings.Join(uids, ",")))
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	obj.SetCreationTimestamp(created)
+	obj.SetManagedFields(managedFields)
+	merged.Object = obj
+	return merged, nil
+}
+
+// keySourcesFor - returns the source every key of the copies comes from, after spec.data and spec.template
+// are applied to the merged data, it returns nil for an instance with a single source
+func keySourcesFor(instance syncInstance, src *sourceObject) map[string]string {
+	if len(src.parts) == 0 {
+		return nil
+	}
+	// spec.data filters and renames the keys, running it over the origins of the keys
+	// moves every origin to the key it ends up in
+	origins := make(map[string][]byte, len(src.keySources))
+	for key, source := range src.keySources {
+		origins[key] = []byte(source)
+	}
+	selected, err := selectData(origins, instance.GetSpec().Data)
+	if err != nil {
+		return nil // the same error fails the sync
+	}
+	keySources := make(map[string]string, len(selected))
+	for key, source := range selected {
+		keySources[key] = string(source)
+	}
+	if tmpl := instance.GetSpec().Template; tmpl != nil {
+		for key := range tmpl.Data {
+			keySources[key] = templateKeySource
+		}
+	}
+	return keySources
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("mergeSources", func() {
+	secret := func(ns, name, version string, data map[string]string) *sourceObject {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, ResourceVersion: version},
+			Type:       corev1.SecretTypeBasicAuth,
+			Data:       map[string][]byte{},
+		}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return newSourceObject(s)
+	}
+
+	var instance *syncv1alpha1.SecretSync
+	BeforeEach(func() {
+		instance = &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{
+			SourceNamespace: "default",
+			TargetName:      "app",
+			Sources: []syncv1alpha1.SourceRef{
+				{Name: "db"},
+				{Name: "cache", Namespace: "platform", Include: []string{"pass*", "host"}, Prefix: "cache-"},
+				{Name: "overrides", Exclude: []string{"internal-*"}},
+			},
+		}}
+	})
+
+	It("should let later sources win and apply the filters and prefix of every source", func() {
+		merged, err := mergeSources(instance, []*sourceObject{
+			secret("default", "db", "1", map[string]string{"host": "db", "password": "a", "cache-host": "old"}),
+			secret("platform", "cache", "7", map[string]string{"host": "redis", "password": "b", "user": "u"}),
+			secret("default", "overrides", "3", map[string]string{"host": "db-2", "internal-token": "x"}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.data).To(Equal(map[string][]byte{
+			"host":           []byte("db-2"),
+			"password":       []byte("a"),
+			"cache-host":     []byte("redis"),
+			"cache-password": []byte("b"),
+		}))
+		Expect(merged.keySources).To(Equal(map[string]string{
+			"host":           "default/overrides",
+			"password":       "default/db",
+			"cache-host":     "platform/cache",
+			"cache-password": "platform/cache",
+		}))
+		Expect(merged.secretType).To(Equal(corev1.SecretTypeOpaque))
+		Expect(merged.GetName()).To(Equal("db+platform/cache+overrides"))
+		Expect(merged.GetResourceVersion()).To(Equal("1,7,3"))
+		Expect(merged.objects()).To(HaveLen(3))
+	})
+
+	It("should reject an invalid key pattern", func() {
+		instance.Spec.Sources[0].Include = []string{"["}
+		_, err := mergeSources(instance, []*sourceObject{
+			secret("default", "db", "1", nil), secret("platform", "cache", "1", nil), secret("default", "overrides", "1", nil),
+		})
+		Expect(err).To(MatchError(ContainSubstring("spec.sources[0]")))
+	})
+
+	It("should report where every key of the copies comes from after spec.data and spec.template", func() {
+		instance.Spec.Sources = instance.Spec.Sources[:2]
+		instance.Spec.Data = &syncv1alpha1.DataSpec{Exclude: []string{"password"}}
+		instance.Spec.Template = &syncv1alpha1.TemplateSpec{Data: map[string]string{"url": "{{ .Data.host }}"}}
+		merged, err := mergeSources(instance, []*sourceObject{
+			secret("default", "db", "1", map[string]string{"host": "db", "password": "a"}),
+			secret("platform", "cache", "1", map[string]string{"host": "redis"}),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keySourcesFor(instance, merged)).To(Equal(map[string]string{
+			"host":       "default/db",
+			"cache-host": "platform/cache",
+			"url":        templateKeySource,
+		}))
+		Expect(keySourcesFor(instance, merged.parts[0])).To(BeNil())
+	})
+})
+
+var _ = Describe("indexBySource", func() {
+	It("should index every entry of spec.sources", func() {
+		instance := &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{
+			SourceNamespace: "default",
+			Sources:         []syncv1alpha1.SourceRef{{Name: "db"}, {Name: "cache", Namespace: "platform"}},
+		}}
+		Expect(indexBySource(instance)).To(Equal([]string{
+			sourceIndexValue(syncv1alpha1.SourceKindSecret, "default", "db"),
+			sourceIndexValue(syncv1alpha1.SourceKindSecret, "platform", "cache"),
+		}))
+	})
+})
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// controllerKeyPrefix is the prefix of the labels and annotations the controller owns
+	controllerKeyPrefix = "secretsync.example.com/"
+	// sourceHashAnnotation is the hash of the type and data of a copy, see contentHash
+	sourceHashAnnotation = controllerKeyPrefix + "source-hash"
+	// sourceResourceVersionAnnotation is the resourceVersion of the source the data of a copy was read from
+	sourceResourceVersionAnnotation = controllerKeyPrefix + "source-resource-version"
+	// sourceUIDAnnotation is the uid of the source of a copy
+	sourceUIDAnnotation = controllerKeyPrefix + "source-uid"
+	// metadataHashAnnotation is the hash of the labels and annotations we wrote to a copy, see metadataHash
+	metadataHashAnnotation = controllerKeyPrefix + "metadata-hash"
+	// reconcileAtAnnotation is set by users on a SecretSync or ClusterSecretSync, every new value
+	// forces a re-apply of every copy, see pendingReconcileRequest
+	reconcileAtAnnotation = controllerKeyPrefix + "reconcile-at"
+)
+
+// isControllerKey - returns true if the label or annotation is used by the controller to track
+// its copies, users are never allowed to set or copy these keys
+func isControllerKey(key string) bool {
+	return key == controllerNameKey || strings.HasPrefix(key, controllerKeyPrefix)
+}
+
+// ownershipLabels - returns the labels that mark a copy as owned by the instance, the uid of the
+// instance is used because it is stable and unique, unlike its name
+func ownershipLabels(instance syncInstance) map[string]string {
+	return map[string]string{
+		controllerNameKey:     controllerNameValue,
+		controllerOwnerUIDKey: string(instance.GetUID()),
+	}
+}
+
+// ownershipAnnotations - returns the annotations that tell humans which instance owns a copy
+func ownershipAnnotations(instance syncInstance) map[string]string {
+	return map[string]string{
+		controllerOwnerNameKey:      instance.GetName(),
+		controllerOwnerNamespacekey: instance.GetNamespace(),
+	}
+}
+
+// legacyOwnershipLabels - returns the labels copies had before they were labelled with the uid of their owner
+func legacyOwnershipLabels(instance syncInstance) map[string]string {
+	return map[string]string{
+		controllerNameKey:           controllerNameValue,
+		controllerOwnerNameKey:      instance.GetName(),
+		controllerOwnerNamespacekey: instance.GetNamespace(),
+	}
+}
+
+// isOwnedBy - returns true if the copy is owned by the instance
+// copies that were written before the uid label existed are identified by the name and namespace
+// of their owner, they are adopted and get the uid label on the next apply
+func isOwnedBy(obj client.Object, instance syncInstance) bool {
+	labels := obj.GetLabels()
+	if ownerUID, ok := labels[controllerOwnerUIDKey]; ok {
+		return ownerUID == string(instance.GetUID())
+	}
+	for key, value := range legacyOwnershipLabels(instance) {
+		if v, ok := labels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// isManaged - returns true if the object is a copy written by the controller, for any instance,
+// copies written before the uid label existed only carry the managed-by label and the legacy owner labels
+func isManaged(obj client.Object) bool {
+	labels := obj.GetLabels()
+	return labels[controllerNameKey] == controllerNameValue || labels[controllerOwnerUIDKey] != ""
+}
+
+// desiredMetadata - returns the labels or annotations of a copy, the keys of the source selected by rules,
+// then the static keys of rules and finally the ownership keys of the instance which always win
+func desiredMetadata(
+	rules *syncv1alpha1.MetadataRules, // spec.metadata.labels or spec.metadata.annotations, may be nil
+	source map[string]string, // the labels or annotations of the source
+	ownership map[string]string, // the keys the controller needs to track the copy
+) map[string]string {
+	metadata := make(map[string]string, len(ownership))
+	if rules != nil {
+		for key, value := range source {
+			if isControllerKey(key) {
+				continue
+			}
+			if slices.Contains(rules.Keys, key) || slices.ContainsFunc(rules.Prefixes, func(prefix string) bool {
+				return strings.HasPrefix(key, prefix)
+			}) {
+				metadata[key] = value
+			}
+		}
+		for key, value := range rules.Static {
+			if !isControllerKey(key) {
+				metadata[key] = value
+			}
+		}
+	}
+	for key, value := range ownership {
+		metadata[key] = value
+	}
+	return metadata
+}
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("desiredMetadata", func() {
+	ownership := map[string]string{
+		controllerNameKey:           controllerNameValue,
+		controllerOwnerNameKey:      "sync-my-secret",
+		controllerOwnerNamespacekey: "default",
+	}
+	source := map[string]string{
+		"cost-center":                     "42",
+		"reloader.stakater.com/match":     "true",
+		"team":                            "platform",
+		exportToAnnotation:                "*",
+		controllerNameKey:                 "someone-else",
+		"kubernetes.io/service-account.x": "ignored",
+	}
+
+	It("should only set the ownership keys without rules", func() {
+		Expect(desiredMetadata(nil, source, ownership)).To(Equal(ownership))
+	})
+
+	It("should copy the selected keys and add the static keys", func() {
+		metadata := desiredMetadata(&syncv1alpha1.MetadataRules{
+			Keys:     []string{"cost-center"},
+			Prefixes: []string{"reloader.stakater.com/", "secretsync.example.com/"},
+			Static:   map[string]string{"env": "prod", "cost-center": "7"},
+		}, source, ownership)
+		Expect(metadata).To(HaveKeyWithValue("cost-center", "7"))
+		Expect(metadata).To(HaveKeyWithValue("reloader.stakater.com/match", "true"))
+		Expect(metadata).To(HaveKeyWithValue("env", "prod"))
+		Expect(metadata).NotTo(HaveKey("team"))
+		Expect(metadata).NotTo(HaveKey(exportToAnnotation))
+	})
+
+	It("should never let the rules overwrite the ownership keys", func() {
+		metadata := desiredMetadata(&syncv1alpha1.MetadataRules{
+			Keys:   []string{controllerNameKey},
+			Static: map[string]string{controllerOwnerNameKey: "other"},
+		}, source, ownership)
+		Expect(metadata).To(Equal(ownership))
+	})
+})
+
+var _ = Describe("isOwnedBy", func() {
+	instance := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{
+		Name:      "sync-my-secret",
+		Namespace: "default",
+		UID:       types.UID("uid-1"),
+	}}
+	copyWithLabels := func(labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "team-a", Labels: labels}}
+	}
+
+	It("should own copies labelled with its uid only", func() {
+		Expect(isOwnedBy(copyWithLabels(ownershipLabels(instance)), instance)).To(BeTrue())
+		Expect(isOwnedBy(copyWithLabels(map[string]string{
+			controllerNameKey:     controllerNameValue,
+			controllerOwnerUIDKey: "uid-of-a-deleted-instance",
+		}), instance)).To(BeFalse())
+		Expect(isOwnedBy(copyWithLabels(nil), instance)).To(BeFalse())
+	})
+
+	It("should adopt copies with the legacy ownership labels", func() {
+		Expect(isOwnedBy(copyWithLabels(legacyOwnershipLabels(instance)), instance)).To(BeTrue())
+	})
+})
+
+var _ = Describe("isManaged", func() {
+	copyWithLabels := func(labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "team-a", Labels: labels}}
+	}
+
+	It("should recognise copies with the uid label and copies with the legacy ownership labels", func() {
+		instance := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "platform", UID: "uid-2"}}
+		Expect(isManaged(copyWithLabels(ownershipLabels(instance)))).To(BeTrue())
+		Expect(isManaged(copyWithLabels(legacyOwnershipLabels(instance)))).To(BeTrue())
+		Expect(isManaged(copyWithLabels(map[string]string{"app": "db"}))).To(BeFalse())
+		Expect(isManaged(copyWithLabels(nil))).To(BeFalse())
+	})
+})
+
+var _ = Describe("ownerRequests", func() {
+	managedCopy := func(ownerNamespace string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-secret",
+			Namespace: "team-a",
+			Labels:    map[string]string{controllerNameKey: controllerNameValue, controllerOwnerUIDKey: "uid-1"},
+			Annotations: map[string]string{
+				controllerOwnerNameKey:      "sync-my-secret",
+				controllerOwnerNamespacekey: ownerNamespace,
+			},
+		}}
+	}
+
+	It("should map a copy to its owner of the same kind only", func() {
+		Expect(ownerRequests(&syncv1alpha1.SecretSyncList{}, managedCopy("default"))).To(ConsistOf(
+			ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "sync-my-secret"}}))
+		Expect(ownerRequests(&syncv1alpha1.ClusterSecretSyncList{}, managedCopy("default"))).To(BeEmpty())
+		Expect(ownerRequests(&syncv1alpha1.ClusterSecretSyncList{}, managedCopy(""))).To(ConsistOf(
+			ctrl.Request{NamespacedName: types.NamespacedName{Name: "sync-my-secret"}}))
+	})
+
+	It("should ignore objects that are not managed by the controller", func() {
+		Expect(ownerRequests(&syncv1alpha1.SecretSyncList{}, &corev1.Secret{})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// the labels that identify the instance a metric belongs to
+const (
+	metricLabelKind            = "kind"             // SecretSync or ClusterSecretSync
+	metricLabelNamespace       = "namespace"        // the namespace of the instance, empty for a ClusterSecretSync
+	metricLabelName            = "name"             // the name of the instance
+	metricLabelTargetNamespace = "target_namespace" // the namespace of the copy
+	metricLabelResult          = "result"           // the result of a sync, see syncv1alpha1.TargetResult
+)
+
+var (
+	// syncsTotal counts the writes of a copy into every target namespace and the failed attempts,
+	// a copy that is already up to date is not written and not counted
+	syncsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "secretsync_target_syncs_total",
+		Help: "Number of writes of a copy into a target namespace and of failed attempts, by result.",
+	}, []string{metricLabelKind, metricLabelNamespace, metricLabelName, metricLabelTargetNamespace, metricLabelResult})
+
+	// lastSuccessTimestamp is the last time every target of an instance was synced successfully
+	lastSuccessTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "secretsync_last_success_timestamp_seconds",
+		Help: "Unix time of the last sync in which every target namespace was synced successfully.",
+	}, []string{metricLabelKind, metricLabelNamespace, metricLabelName})
+
+	// managedTargets is the number of copies an instance currently manages
+	managedTargets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "secretsync_managed_targets",
+		Help: "Number of copies that are in sync with the source.",
+	}, []string{metricLabelKind, metricLabelNamespace, metricLabelName})
+
+	// conflictsTotal counts the existing objects an instance was not allowed to take over
+	conflictsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "secretsync_conflicts_total",
+		Help: "Number of times an existing object in a target namespace could not be taken over.",
+	}, []string{metricLabelKind, metricLabelNamespace, metricLabelName, metricLabelTargetNamespace})
+
+	// driftCorrectionsTotal counts the copies that were changed or deleted by someone else and restored
+	driftCorrectionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "secretsync_drift_corrections_total",
+		Help: "Number of times a copy that was changed or deleted outside of the controller was restored.",
+	}, []string{metricLabelKind, metricLabelNamespace, metricLabelName, metricLabelTargetNamespace})
+
+	// propagationLatency is the time between a change of the source and the copy being written
+	propagationLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "secretsync_propagation_latency_seconds",
+		Help:    "Time between a change of the source and the successful write of a copy with the change.",
+		Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900},
+	}, []string{metricLabelKind})
+)
+
+func init() {
+	// register the custom metrics with the global prometheus registry of controller-runtime,
+	// they are served on the metrics endpoint of the manager
+	metrics.Registry.MustRegister(syncsTotal, lastSuccessTimestamp, managedTargets, conflictsTotal,
+		driftCorrectionsTotal, propagationLatency)
+}
+
+// instanceKind - returns the kind of the instance for the metric labels
+func instanceKind(instance syncInstance) string {
+	if isClusterScoped(instance) {
+		return "ClusterSecretSync"
+	}
+	return "SecretSync"
+}
+
+// instanceLabels - returns the labels that identify the instance in the metrics
+func instanceLabels(instance syncInstance) prometheus.Labels {
+	return prometheus.Labels{
+		metricLabelKind:      instanceKind(instance),
+		metricLabelNamespace: instance.GetNamespace(),
+		metricLabelName:      instance.GetName(),
+	}
+}
+
+// recordTargetMetrics - records the state of the targets after a sync in the metrics
+// an instance without targets has not synced anything, so its last success is left alone
+func recordTargetMetrics(instance syncInstance, targets []syncv1alpha1.TargetStatus) {
+	synced := 0
+	for _, target := range targets {
+		if target.Result == syncv1alpha1.TargetResultSynced {
+			synced++
+		}
+	}
+	managedTargets.With(instanceLabels(instance)).Set(float64(synced))
+	if len(targets) > 0 && synced == len(targets) {
+		lastSuccessTimestamp.With(instanceLabels(instance)).SetToCurrentTime()
+	}
+}
+
+// recordSyncMetric - counts a write of a copy into a target namespace, or a failed attempt
+func recordSyncMetric(instance syncInstance, targetNamespace string, result syncv1alpha1.TargetResult) {
+	syncsTotal.WithLabelValues(instanceKind(instance), instance.GetNamespace(), instance.GetName(),
+		targetNamespace, string(result)).Inc()
+}
+
+// recordConflictMetric - counts an existing object the instance was not allowed to take over
+func recordConflictMetric(instance syncInstance, targetNamespace string) {
+	conflictsTotal.WithLabelValues(instanceKind(instance), instance.GetNamespace(), instance.GetName(),
+		targetNamespace).Inc()
+}
+
+// recordDriftMetric - counts a copy that was restored after it was changed or deleted by someone else
+func recordDriftMetric(instance syncInstance, targetNamespace string) {
+	driftCorrectionsTotal.WithLabelValues(instanceKind(instance), instance.GetNamespace(), instance.GetName(),
+		targetNamespace).Inc()
+}
+
+// recordPropagationLatency - observes the time between the last change of the source and now
+func recordPropagationLatency(instance syncInstance, src *sourceObject) {
+	changed := sourceChangeTime(src)
+	if changed.IsZero() {
+		return
+	}
+	propagationLatency.WithLabelValues(instanceKind(instance)).Observe(time.Since(changed).Seconds())
+}
+
+// sourceChangeTime - returns when the source was last changed, the resourceVersion is opaque so we
+// use the most recent write recorded in the managed fields, or the creation time if there is none
+func sourceChangeTime(src *sourceObject) time.Time {
+	changed := src.GetCreationTimestamp().Time
+	for _, entry := range src.GetManagedFields() {
+		if entry.Time != nil && entry.Time.After(changed) {
+			changed = entry.Time.Time
+		}
+	}
+	return changed
+}
+
+// deleteTargetMetrics - removes the metrics of a target namespace the instance no longer syncs into
+func deleteTargetMetrics(instance syncInstance, targetNamespace string) {
+	labels := instanceLabels(instance)
+	labels[metricLabelTargetNamespace] = targetNamespace
+	syncsTotal.DeletePartialMatch(labels)
+	conflictsTotal.DeletePartialMatch(labels)
+	driftCorrectionsTotal.DeletePartialMatch(labels)
+}
+
+// deleteInstanceMetrics - removes the metrics of an instance that is deleted, so that the metrics
+// endpoint does not keep reporting it
+func deleteInstanceMetrics(instance syncInstance) {
+	labels := instanceLabels(instance)
+	syncsTotal.DeletePartialMatch(labels)
+	lastSuccessTimestamp.DeletePartialMatch(labels)
+	managedTargets.DeletePartialMatch(labels)
+	conflictsTotal.DeletePartialMatch(labels)
+	driftCorrectionsTotal.DeletePartialMatch(labels)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("sourceChangeTime", func() {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("should use the most recent managed fields entry", func() {
+		updated := metav1.NewTime(created.Add(time.Hour))
+		src := newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.NewTime(created),
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl", Time: &updated},
+				{Manager: "kube-controller-manager"},
+			},
+		}})
+		Expect(sourceChangeTime(src)).To(Equal(updated.Time))
+	})
+
+	It("should fall back to the creation time", func() {
+		src := newSourceObject(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}})
+		Expect(sourceChangeTime(src)).To(Equal(created))
+	})
+})
+
+// gaugeValue - returns the current value of a gauge
+func gaugeValue(gauge prometheus.Gauge) float64 {
+	metric := &dto.Metric{}
+	Expect(gauge.Write(metric)).To(Succeed())
+	return metric.GetGauge().GetValue()
+}
+
+var _ = Describe("target metrics", func() {
+	var instance *syncv1alpha1.SecretSync
+	BeforeEach(func() {
+		instance = &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "default"}}
+		DeferCleanup(deleteInstanceMetrics, instance)
+	})
+
+	It("should only record a last success when every target of at least one was synced", func() {
+		recordTargetMetrics(instance, nil)
+		Expect(gaugeValue(lastSuccessTimestamp.With(instanceLabels(instance)))).To(BeZero())
+
+		recordTargetMetrics(instance, []syncv1alpha1.TargetStatus{
+			{Namespace: "team-a", Result: syncv1alpha1.TargetResultSynced},
+			{Namespace: "team-b", Result: syncv1alpha1.TargetResultDenied},
+		})
+		Expect(gaugeValue(lastSuccessTimestamp.With(instanceLabels(instance)))).To(BeZero())
+		Expect(gaugeValue(managedTargets.With(instanceLabels(instance)))).To(Equal(1.0))
+
+		recordTargetMetrics(instance, []syncv1alpha1.TargetStatus{
+			{Namespace: "team-a", Result: syncv1alpha1.TargetResultSynced},
+		})
+		Expect(gaugeValue(lastSuccessTimestamp.With(instanceLabels(instance)))).NotTo(BeZero())
+	})
+
+	It("should remove the series of a target namespace only", func() {
+		recordSyncMetric(instance, "team-a", syncv1alpha1.TargetResultSynced)
+		recordSyncMetric(instance, "team-b", syncv1alpha1.TargetResultFailed)
+		recordConflictMetric(instance, "team-a")
+
+		deleteTargetMetrics(instance, "team-a")
+		Expect(syncsTotal.DeleteLabelValues("SecretSync", "default", "metrics", "team-a", "Synced")).To(BeFalse())
+		Expect(conflictsTotal.DeleteLabelValues("SecretSync", "default", "metrics", "team-a")).To(BeFalse())
+		Expect(syncsTotal.DeleteLabelValues("SecretSync", "default", "metrics", "team-b", "Failed")).To(BeTrue())
+	})
+})
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// byTargetSecretIndexKey is the key of the index from a copy ("<kind>/<namespace>/<name>") to the instances that write it
+const byTargetSecretIndexKey = "byTargetSecret"
+
+// indexByTarget - returns an index value for every copy the instance writes, the copies in spec.targetNamespaces
+// and the copies in status.targets, the latter covers the namespaces matched by the targetNamespaceSelector
+func indexByTarget(rawObj client.Object) []string {
+	instance, ok := rawObj.(syncInstance)
+	if !ok {
+		return nil
+	}
+	kind := sourceKindOf(instance)
+	var values []string
+	for _, ns := range instance.GetSpec().TargetNamespaces {
+		values = append(values, sourceIndexValue(kind, ns, targetNameFor(instance, ns)))
+	}
+	for _, target := range instance.GetStatus().Targets {
+		if target.Name != "" {
+			values = append(values, sourceIndexValue(kind, target.Namespace, target.Name))
+		}
+	}
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// targetContenders - returns every instance that writes the copy name in namespace ns, including instance itself,
+// ordered oldest first, the first one wins the arbitration and is the only one that writes the copy
+// instances that are being deleted are about to release the copy, and instances that are denied
+// the namespace never write into it, so neither of them contends for the copy
+func (r *SecretSyncReconciler) targetContenders(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that called the reconcile function
+	ns string, // the namespace of the copy
+	name string, // the name of the copy
+) ([]syncInstance, error) {
+	value := sourceIndexValue(sourceKindOf(instance), ns, name)
+	contenders := []syncInstance{instance}
+	for _, list := range []client.ObjectList{&syncv1alpha1.SecretSyncList{}, &syncv1alpha1.ClusterSecretSyncList{}} {
+		if err := r.List(ctx, list, client.MatchingFields{byTargetSecretIndexKey: value}); err != nil {
+			return nil, fmt.Errorf("error listing the instances that sync %s: %w", value, err)
+		}
+		_ = meta.EachListItem(list, func(obj runtime.Object) error {
+			other, ok := obj.(syncInstance)
+			if !ok || other.GetUID() == instance.GetUID() || !other.GetDeletionTimestamp().IsZero() || isDeniedIn(other, ns) {
+				return nil
+			}
+			contenders = append(contenders, other)
+			return nil
+		})
+	}
+	slices.SortStableFunc(contenders, compareAge)
+	return contenders, nil
+}
+
+// compareAge - orders instances by creationTimestamp, instances created in the same second
+// are ordered by their namespace and name so that every reconcile picks the same winner
+func compareAge(a, b syncInstance) int {
+	if c := a.GetCreationTimestamp().Compare(b.GetCreationTimestamp().Time); c != 0 {
+		return c
+	}
+	return strings.Compare(instanceRef(a), instanceRef(b))
+}
+
+// isDeniedIn - returns true if the last sync of the instance was denied the namespace
+func isDeniedIn(instance syncInstance, ns string) bool {
+	for _, target := range instance.GetStatus().Targets {
+		if target.Namespace == ns {
+			return target.Result == syncv1alpha1.TargetResultDenied
+		}
+	}
+	return false
+}
+
+// describeInstance - returns the kind and the name of the instance as it is shown in messages
+func describeInstance(instance syncInstance) string {
+	if isClusterScoped(instance) {
+		return "ClusterSecretSync " + instance.GetName()
+	}
+	return "SecretSync " + instance.GetNamespace() + "/" + instance.GetName()
+}
+
+// conflictingNamespaces - returns the target namespaces another instance won the arbitration for
+func conflictingNamespaces(instance syncInstance) []string {
+	var namespaces []string
+	for _, target := range instance.GetStatus().Targets {
+		if target.Result == syncv1alpha1.TargetResultConflict {
+			namespaces = append(namespaces, target.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// overlapChangedPredicate passes the events that can change the arbitration of a copy: creation, deletion,
+// changes of the spec, which bump the generation, and status updates that change the copies in status.targets
+// other status updates are filtered out, otherwise overlapping instances would re-queue each other forever
+var overlapChangedPredicate = predicate.Or(predicate.GenerationChangedPredicate{}, predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return !slices.Equal(indexByTarget(e.ObjectOld), indexByTarget(e.ObjectNew))
+	},
+})
+
+// mapOverlappingToSyncs returns a reconcile request for every item of list that writes one of the copies of obj.
+// A SecretSync or ClusterSecretSync that is created, changed or deleted can win or lose the arbitration
+// for the copies it shares, so the other instances are re-queued to take over or release those copies.
+func (r *SecretSyncReconciler) mapOverlappingToSyncs(
+	ctx context.Context,
+	list client.ObjectList, // an empty SecretSyncList or ClusterSecretSyncList
+	obj client.Object, // the changed SecretSync or ClusterSecretSync
+) []ctrl.Request {
+	var reqs []ctrl.Request
+	for _, value := range indexByTarget(obj) {
+		if err := r.List(ctx, list, client.MatchingFields{byTargetSecretIndexKey: value}); err != nil {
+			continue // on error do not requeue
+		}
+		reqs = append(reqs, requestsFor(list, func(instance syncInstance) bool {
+			return instance.GetUID() != obj.GetUID()
+		})...)
+	}
+	return reqs
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("indexByTarget", func() {
+	It("should index the copies in spec.targetNamespaces and status.targets once", func() {
+		instance := &syncv1alpha1.SecretSync{
+			Spec: syncv1alpha1.SecretSyncSpec{
+				SourceName:          "db",
+				TargetNamespaces:    []string{"team-a", "team-b"},
+				TargetNameOverrides: map[string]string{"team-b": "app-db"},
+			},
+			Status: syncv1alpha1.SecretSyncStatus{Targets: []syncv1alpha1.TargetStatus{
+				{Namespace: "team-a", Name: "db"},
+				{Namespace: "selected", Name: "db"},
+			}},
+		}
+		Expect(indexByTarget(instance)).To(Equal([]string{
+			"Secret/selected/db", "Secret/team-a/db", "Secret/team-b/app-db",
+		}))
+	})
+})
+
+var _ = Describe("compareAge", func() {
+	It("should order by creationTimestamp, then by namespace and name", func() {
+		now := time.Now()
+		older := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-b", Name: "db", CreationTimestamp: metav1.NewTime(now.Add(-time.Hour)),
+		}}
+		newer := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-a", Name: "db", CreationTimestamp: metav1.NewTime(now),
+		}}
+		sameSecond := &syncv1alpha1.SecretSync{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team-c", Name: "db", CreationTimestamp: metav1.NewTime(now),
+		}}
+		Expect(compareAge(older, newer)).To(Equal(-1))
+		Expect(compareAge(newer, older)).To(Equal(1))
+		Expect(compareAge(newer, sameSecond)).To(Equal(-1))
+	})
+})
+
+var _ = Describe("setConflictCondition", func() {
+	It("should name the winners while targets are in conflict and remove the condition afterwards", func() {
+		instance := &syncv1alpha1.SecretSync{Status: syncv1alpha1.SecretSyncStatus{Targets: []syncv1alpha1.TargetStatus{
+			{Namespace: "team-a", Name: "db", Result: syncv1alpha1.TargetResultSynced},
+			{Namespace: "team-b", Name: "db", Result: syncv1alpha1.TargetResultConflict,
+				LastError: "the Secret db in namespace team-b is synced by SecretSync default/first, which was created first"},
+		}}}
+		setConflictCondition(instance)
+		condition := meta.FindStatusCondition(instance.Status.Conditions, conflictConditionType)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		Expect(condition.Message).To(ContainSubstring("SecretSync default/first"))
+		Expect(conflictingNamespaces(instance)).To(Equal([]string{"team-b"}))
+
+		instance.Status.Targets[1].Result = syncv1alpha1.TargetResultSynced
+		setConflictCondition(instance)
+		Expect(meta.FindStatusCondition(instance.Status.Conditions, conflictConditionType)).To(BeNil())
+	})
+})
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultRetryBaseDelay is the delay before the first retry of a transient error
+	DefaultRetryBaseDelay = time.Second
+	// DefaultRetryMaxDelay is the longest delay between retries, permanent errors are retried after this delay
+	DefaultRetryMaxDelay = 5 * time.Minute
+)
+
+// permanentError marks an error that will not go away by retrying, for example an invalid spec
+// or an object that is owned by someone else, it needs a change by the user
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// permanent - marks err as permanent, it returns nil if err is nil
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// isPermanent - returns true if err is permanent, errors that were joined together are only permanent
+// if all of them are, one transient error is enough to retry with backoff
+func isPermanent(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !isPermanent(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// errorResult - returns the result of a reconcile that failed with err
+// transient errors are returned to controller-runtime, so that the rate limiter of the workqueue
+// retries them with exponential backoff, permanent errors are not retried hot but after the max delay,
+// a change of the instance or of the objects we watch triggers a new reconcile anyway
+func (r *SecretSyncReconciler) errorResult(err error) (ctrl.Result, error) {
+	if isPermanent(err) {
+		return ctrl.Result{RequeueAfter: r.retryMaxDelay()}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// retryMaxDelay - returns the longest delay between retries
+func (r *SecretSyncReconciler) retryMaxDelay() time.Duration {
+	if r.RetryMaxDelay > 0 {
+		return r.RetryMaxDelay
+	}
+	return DefaultRetryMaxDelay
+}
+
+// rateLimiter - returns the rate limiter of the workqueue, every controller needs its own instance
+// like the default rate limiter of controller-runtime, the per item exponential backoff is combined
+// with an overall bucket limiter so that a burst of failures does not flood the API server
+func (r *SecretSyncReconciler) rateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	base := r.RetryBaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](base, r.retryMaxDelay()),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("isPermanent", func() {
+	transient := errors.New("connection refused")
+	conflict := permanent(errors.New("the secret is owned by someone else"))
+
+	It("should detect wrapped permanent errors", func() {
+		Expect(isPermanent(conflict)).To(BeTrue())
+		Expect(isPermanent(fmt.Errorf("syncing team-a: %w", conflict))).To(BeTrue())
+		Expect(isPermanent(transient)).To(BeFalse())
+		Expect(permanent(nil)).To(BeNil())
+	})
+
+	It("should retry joined errors with backoff if one of them is transient", func() {
+		Expect(isPermanent(errors.Join(conflict, conflict))).To(BeTrue())
+		Expect(isPermanent(errors.Join(conflict, transient))).To(BeFalse())
+	})
+})
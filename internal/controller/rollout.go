@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restartedAtAnnotation is set on the pod template of a workload to restart it, like kubectl rollout restart does
+const restartedAtAnnotation = controllerKeyPrefix + "restarted-at"
+
+// workload is a Deployment, StatefulSet or DaemonSet together with its pod template
+type workload struct {
+	client.Object
+	template *corev1.PodTemplateSpec
+}
+
+// restartWorkloads - restarts the workloads of the kinds in spec.rolloutTargets that reference the copy
+func (r *SecretSyncReconciler) restartWorkloads(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that owns the copy
+	copySecret client.Object, // the copy whose content changed
+) error {
+	var combineErr error
+	for _, kind := range instance.GetSpec().RolloutTargets {
+		workloads, err := r.listWorkloads(ctx, kind, copySecret.GetNamespace())
+		if err != nil {
+			combineErr = errors.Join(combineErr, err)
+			continue
+		}
+		for _, w := range workloads {
+			if !podSpecReferences(&w.template.Spec, kindOfObject(copySecret), copySecret.GetName()) {
+				continue
+			}
+			patch := client.MergeFrom(w.DeepCopyObject().(client.Object))
+			if w.template.Annotations == nil {
+				w.template.Annotations = make(map[string]string, 1)
+			}
+			w.template.Annotations[restartedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			if err := r.Patch(ctx, w.Object, patch); err != nil {
+				combineErr = errors.Join(combineErr, fmt.Errorf("error restarting %s %s in namespace %s: %w",
+					kind, w.GetName(), w.GetNamespace(), err))
+				continue
+			}
+			r.recordTargetEvent(instance, w.Object, corev1.EventTypeNormal, eventReasonRolloutRestarted,
+				"restarted %s %s in namespace %s because %s %s changed",
+				kind, w.GetName(), w.GetNamespace(), kindOfObject(copySecret), copySecret.GetName())
+		}
+	}
+	return combineErr
+}
+
+// listWorkloads - returns the workloads of a kind in a namespace
+// the workloads are read from the API server, listing them through the cached client would start an informer
+// that keeps every Deployment, StatefulSet and DaemonSet of the cluster in memory, only to restart a few of them
+func (r *SecretSyncReconciler) listWorkloads(
+	ctx context.Context, // context for the API call
+	kind syncv1alpha1.RolloutTarget, // the kind of workloads to list
+	namespace string, // the namespace of the copy
+) ([]workload, error) {
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+
+	var workloads []workload
+	switch kind {
+	case syncv1alpha1.RolloutTargetDeployment:
+		var list appsv1.DeploymentList
+		if err := reader.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing deployments in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			workloads = append(workloads, workload{Object: &list.Items[i], template: &list.Items[i].Spec.Template})
+		}
+	case syncv1alpha1.RolloutTargetStatefulSet:
+		var list appsv1.StatefulSetList
+		if err := reader.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing statefulsets in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			workloads = append(workloads, workload{Object: &list.Items[i], template: &list.Items[i].Spec.Template})
+		}
+	case syncv1alpha1.RolloutTargetDaemonSet:
+		var list appsv1.DaemonSetList
+		if err := reader.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("error listing daemonsets in namespace %s: %w", namespace, err)
+		}
+		for i := range list.Items {
+			workloads = append(workloads, workload{Object: &list.Items[i], template: &list.Items[i].Spec.Template})
+		}
+	}
+	return workloads, nil
+}
+
+// podSpecReferences - returns true if the pod spec consumes the Secret or ConfigMap called name
+// through env, envFrom or volumes, including projected volumes
+func podSpecReferences(spec *corev1.PodSpec, kind syncv1alpha1.SourceKind, name string) bool {
+	isSecret := kind == syncv1alpha1.SourceKindSecret
+
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, c := range containers {
+			for _, env := range c.Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if isSecret && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name {
+					return true
+				}
+				if !isSecret && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name {
+					return true
+				}
+			}
+			for _, envFrom := range c.EnvFrom {
+				if isSecret && envFrom.SecretRef != nil && envFrom.SecretRef.Name == name {
+					return true
+				}
+				if !isSecret && envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == name {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if isSecret && volume.Secret != nil && volume.Secret.SecretName == name {
+			return true
+		}
+		if !isSecret && volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+			return true
+		}
+		if volume.Projected == nil {
+			continue
+		}
+		for _, source := range volume.Projected.Sources {
+			if isSecret && source.Secret != nil && source.Secret.Name == name {
+				return true
+			}
+			if !isSecret && source.ConfigMap != nil && source.ConfigMap.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("podSpecReferences", func() {
+	It("should find a Secret referenced through env, envFrom, volumes and projected volumes", func() {
+		env := &corev1.PodSpec{Containers: []corev1.Container{{Env: []corev1.EnvVar{{
+			Name: "PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "db"}, Key: "password",
+			}},
+		}}}}}
+		Expect(podSpecReferences(env, syncv1alpha1.SourceKindSecret, "db")).To(BeTrue())
+		Expect(podSpecReferences(env, syncv1alpha1.SourceKindSecret, "other")).To(BeFalse())
+		Expect(podSpecReferences(env, syncv1alpha1.SourceKindConfigMap, "db")).To(BeFalse())
+
+		envFrom := &corev1.PodSpec{InitContainers: []corev1.Container{{EnvFrom: []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+		}}}}}
+		Expect(podSpecReferences(envFrom, syncv1alpha1.SourceKindSecret, "db")).To(BeTrue())
+
+		volume := &corev1.PodSpec{Volumes: []corev1.Volume{{Name: "creds", VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: "db"},
+		}}}}
+		Expect(podSpecReferences(volume, syncv1alpha1.SourceKindSecret, "db")).To(BeTrue())
+
+		projected := &corev1.PodSpec{Volumes: []corev1.Volume{{Name: "all", VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+				Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}},
+			}}},
+		}}}}
+		Expect(podSpecReferences(projected, syncv1alpha1.SourceKindSecret, "db")).To(BeTrue())
+	})
+
+	It("should find a ConfigMap only through ConfigMap references", func() {
+		spec := &corev1.PodSpec{Volumes: []corev1.Volume{{Name: "config", VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app"}},
+		}}}}
+		Expect(podSpecReferences(spec, syncv1alpha1.SourceKindConfigMap, "app")).To(BeTrue())
+		Expect(podSpecReferences(spec, syncv1alpha1.SourceKindSecret, "app")).To(BeFalse())
+	})
+})
+
+var _ = Describe("listWorkloads", func() {
+	ctx := context.Background()
+
+	It("should read the workloads of the namespace through the API reader", func() {
+		r := newTestReconciler()
+		r.APIReader = newTestReconciler(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "team-a"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "team-b"}},
+		).Client
+		workloads, err := r.listWorkloads(ctx, syncv1alpha1.RolloutTargetDeployment, "team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workloads).To(HaveLen(1))
+		Expect(workloads[0].GetName()).To(Equal("web"))
+	})
+})
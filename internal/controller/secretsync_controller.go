@@ -24,11 +24,13 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -37,26 +39,47 @@ import (
 	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // SecretSyncReconciler reconciles a SecretSync object
 type SecretSyncReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder // records Events on the SecretSyncs and their copies
+	// APIReader reads from the API server without the cache, it is used for the objects we do not want
+	// to keep a cluster wide informer for, such as the workloads that are restarted, the Client is used if it is nil
+	APIReader client.Reader
+	// RetryBaseDelay is the delay before the first retry of a transient error, it doubles on every failure
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries, permanent errors are retried after this delay
+	RetryMaxDelay time.Duration
 }
 
+// syncInstance is implemented by SecretSync and ClusterSecretSync, both kinds share the same
+// spec and status so the sync logic is written once against this interface
+type syncInstance interface {
+	client.Object
+	GetSpec() *syncv1alpha1.SecretSyncSpec
+	GetStatus() *syncv1alpha1.SecretSyncStatus
+}
+
+// ensure both kinds implement syncInstance
+var (
+	_ syncInstance = &syncv1alpha1.SecretSync{}
+	_ syncInstance = &syncv1alpha1.ClusterSecretSync{}
+)
+
 const (
 	// controllerName is the name of the controller
-	annotationsLen = 3 // number of annotations we will add to the copied object
-	//
 	controllerNameKey   = "app.kubernetes.io/managed-by"
 	controllerNameValue = "secret-sync-controller"
 	//
 	controllerOwnerNameKey      = "secretsync.example.com/owner-name"
 	controllerOwnerNamespacekey = "secretsync.example.com/owner-namespace"
+	controllerOwnerUIDKey       = "secretsync.example.com/owner-uid" // label with the uid of the owner of a copy
 	secretSyncFinalizer         = "secretsync.example.com/finalizer" // finalizer to be added to the SecretSync object
-	requeueDelay                = 7 * time.Minute
-	bySourceSecretIndexKey      = "bySourceSecret" // the key to our local index
+	bySourceSecretIndexKey      = "bySourceSecret"                   // the key to our local index
 )
 
 // +kubebuilder:rbac:groups=sync.example.com,resources=secretsyncs,verbs=get;list;watch;create;update;patch;delete
@@ -65,6 +88,10 @@ const (
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=secrets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets;daemonsets,verbs=get;list;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -92,39 +119,56 @@ func (r *SecretSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err // ignore not found errors
 	}
 
+	return r.reconcileInstance(ctx, instance, l)
+}
+
+// reconcileInstance - runs the reconciliation for a SecretSync or a ClusterSecretSync
+// that has been read from the API server
+func (r *SecretSyncReconciler) reconcileInstance(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that called the reconcile function
+	l logr.Logger, // the logger of the calling reconciler
+) (ctrl.Result, error) {
+
 	// check if the instance has our finalizer
 	objectHasFinalizer := controllerutil.ContainsFinalizer(instance, secretSyncFinalizer)
 
 	// Step 2: Handle deletion
-	if !instance.DeletionTimestamp.IsZero() { // CR is marked for deletion
+	if !instance.GetDeletionTimestamp().IsZero() { // CR is marked for deletion
 		// Handle cleanup of synced secrets
 		// Remove finalizer, return
-		l.Info("deleting instance and child resources", "name", instance.Name, "namespace", instance.Namespace)
-		if err := r.deleteChildObjects(ctx, instance); err != nil {
+		l.Info("deleting instance and child resources", "name", instance.GetName(), "namespace", instance.GetNamespace(),
+			"deletionPolicy", instance.GetSpec().DeletionPolicy)
+		cleanedUp, err := r.deleteChildObjects(ctx, instance)
+		if err != nil {
 			l.Error(err, "failed to delete child objects")
+			r.recordEvent(instance, corev1.EventTypeWarning, eventReasonCleanupFailed, "failed to clean up copies: %s", err)
 			msg := fmt.Sprintf("failed to delete child objects: %s", err)
-			if err := r.updateStatus(ctx, instance, msg, true); err != nil {
-				l.Error(err, "failed to update status after error deleting child objects")
+			if uerr := r.updateStatus(ctx, instance, msg, true); uerr != nil {
+				l.Error(uerr, "failed to update status after error deleting child objects")
 			}
 
-			// we want to requeue this request to try again later
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later
+			// we want to requeue this request to try again later with backoff
+			return r.errorResult(err)
 		}
 		// remove finzalizer if it exists
 		if err := r.RemoveFinalizer(ctx, instance, secretSyncFinalizer); err != nil {
 			l.Error(err, "failed to delete finalizer")
-			if err := r.updateStatus(ctx, instance,
-				fmt.Sprintf("%s", err), true); err != nil { // Update status with error
-				l.Error(err, "failed to update status after error removing finalizer")
-				// we want to requeue this request to try again later
+			if uerr := r.updateStatus(ctx, instance,
+				fmt.Sprintf("%s", err), true); uerr != nil { // Update status with error
+				l.Error(uerr, "failed to update status after error removing finalizer")
 			}
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later
+			// we want to requeue this request to try again later with backoff
+			return r.errorResult(err)
 		}
 		if err := r.Update(ctx, instance); err != nil {
 			l.Error(err, "failed to update instance after removing finalizer")
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later
+			return r.errorResult(err) // Try again later with backoff
 		}
-		l.Info("finalizer removed and child resources deleted", "name", instance.Name, "namespace", instance.Namespace)
+		deleteInstanceMetrics(instance)
+		r.recordEvent(instance, corev1.EventTypeNormal, eventReasonCleanedUp,
+			"cleaned up %d copies with deletion policy %s", len(cleanedUp), instance.GetSpec().DeletionPolicy)
+		l.Info("finalizer removed and child resources deleted", "name", instance.GetName(), "namespace", instance.GetNamespace())
 		return ctrl.Result{}, nil // No need to requeue, cleanup done
 	}
 	// Step 3: Add finalizer if not present
@@ -132,78 +176,206 @@ func (r *SecretSyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// this is to ensure that we can clean up the child resources when the CR is deleted
 	if !objectHasFinalizer {
 		if ok := controllerutil.AddFinalizer(instance, secretSyncFinalizer); !ok {
-			l.Error(fmt.Errorf("failed to add finalizer %s to instance %s", secretSyncFinalizer, instance.Name),
-				"failed to add finalizer")
-			return ctrl.Result{RequeueAfter: 7 * time.Minute}, nil // Try again later
+			err := fmt.Errorf("failed to add finalizer %s to instance %s", secretSyncFinalizer, instance.GetName())
+			l.Error(err, "failed to add finalizer")
+			return r.errorResult(err) // Try again later with backoff
 		}
 		if err := r.Update(ctx, instance); err != nil {
 			l.Error(err, "failed to update instance after adding finalizer")
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later
+			return r.errorResult(err) // Try again later with backoff
 		}
 		// Return to requeue and ensure consistent state before continuing
 		// we have added the finalizer, so we can requeue
-		l.Info("finalizer added to instance", "name", instance.Name, "namespace", instance.Namespace)
+		l.Info("finalizer added to instance", "name", instance.GetName(), "namespace", instance.GetNamespace())
 		return ctrl.Result{}, nil
 	}
 
+	// a suspended instance leaves its copies as they are, nothing is written or pruned until spec.suspend is cleared
+	// the deletion above is still handled, so a suspended instance can be deleted and its copies cleaned up
+	if instance.GetSpec().Suspend {
+		l.Info("instance is suspended, skipping sync", "name", instance.GetName(), "namespace", instance.GetNamespace())
+		if err := r.updateSuspendedStatus(ctx, instance); err != nil {
+			l.Error(err, "failed to update status of suspended instance")
+			return r.errorResult(err) // Try again later with backoff
+		}
+		return ctrl.Result{}, nil // No need to requeue, clearing spec.suspend triggers a new reconcile
+	}
+	if meta.IsStatusConditionTrue(instance.GetStatus().Conditions, suspendedConditionType) {
+		// the condition itself is removed with the status update at the end of this sync
+		r.recordEvent(instance, corev1.EventTypeNormal, eventReasonResumed, "sync resumed, spec.suspend was cleared")
+	}
+
 	// check if the source namespace is also part of the destination namespace
 	// we cannot copy the source object to itself
 	if err := checkSourceInTargetNamespaces(instance); err != nil {
 		l.Error(err, "failed to sync")
 		if uerr := r.updateStatus(ctx, instance, err.Error(), true); uerr != nil {
-			return ctrl.Result{}, errors.Join(uerr, err) // Try again later
+			return r.errorResult(uerr) // Try again later with backoff
 		}
-		return ctrl.Result{}, nil // No need to requeue, we have updated the status
+		return ctrl.Result{}, nil // No need to requeue, a fix of the spec triggers a new reconcile
 	}
 	//
-	srcSecret := &corev1.Secret{} // the source secret we need to sync/copy to the target namespaces
-	// try to read the source secret from the source namespace
-	if err := r.Get(ctx, types.NamespacedName{
-		Name:      instance.Spec.SourceName,
-		Namespace: instance.Spec.SourceNamespace,
-	}, srcSecret); err != nil { // if there was any error reading the source secret
+	// try to read the source secret or configmap from the source namespace
+	// this is the object we need to sync/copy to the target namespaces
+	src, err := r.getSourceObject(ctx, instance)
+	if err != nil { // if there was any error reading the source object
 		// we will update the status of the CR and the update the status with the
 		// with correct message and requeue and retry later
-		msg := fmt.Sprintf("error reading source secret %s in namespace %s: %s",
-			instance.Spec.SourceName, instance.Spec.SourceNamespace, err)
+		msg := fmt.Sprintf("error reading source %s %s in namespace %s: %s",
+			strings.ToLower(string(sourceKindOf(instance))), instance.GetSpec().SourceName, instance.GetSpec().SourceNamespace, err)
+		if len(instance.GetSpec().Sources) > 0 {
+			msg = fmt.Sprintf("error reading sources: %s", err)
+		}
 		l.Error(err, msg)
-		if err := r.updateStatus(ctx, instance, msg, true); err != nil {
-			l.Error(err, "failed to update status after error reading source secret")
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later after 7 minutes
+		if apierrors.IsNotFound(err) {
+			r.recordEvent(instance, corev1.EventTypeWarning, eventReasonSourceNotFound, "%s", msg)
+		} else {
+			r.recordEvent(instance, corev1.EventTypeWarning, eventReasonSyncFailed, "%s", msg)
+		}
+		if uerr := r.updateStatus(ctx, instance, msg, true); uerr != nil {
+			l.Error(uerr, "failed to update status after error reading source secret")
+			return r.errorResult(uerr) // Try again later with backoff
+		}
+		// a missing source is not retried hot, creating the source triggers a new reconcile through the source watch
+		if apierrors.IsNotFound(err) {
+			err = permanent(err)
+		}
+		return r.errorResult(err)
+	}
+
+	// a namespaced SecretSync can only read a source from another namespace if the source opted in to being exported
+	if err := checkSourceExported(instance, src); err != nil {
+		l.Error(err, "source is not exported")
+		// the owner of the source may have withdrawn the opt-in, the copies written before must not keep the data,
+		// so they are deleted whatever the prunePolicy says
+		pruned, perr := r.pruneCopies(ctx, instance, nil, nil, syncv1alpha1.PrunePolicyDelete)
+		if len(pruned) > 0 {
+			l.Info("pruned copies of a source that is not exported", "copies", pruned)
+		}
+		instance.GetStatus().PrunedTargets = pruned
+		denyTargets(instance, err.Error())
+		if uerr := r.updateStatusWithReason(ctx, instance, sourceNotExportedReason, err.Error()); uerr != nil {
+			return r.errorResult(errors.Join(uerr, perr))
+		}
+		if perr != nil {
+			return r.errorResult(perr) // Try again later with backoff
 		}
-		return ctrl.Result{RequeueAfter: requeueDelay}, nil // No need to requeue, we have updated the status
+		// No need to requeue, annotating the source triggers a new reconcile through the source watch
+		return ctrl.Result{}, nil
+	}
+
+	// work out the namespaces we need to copy the secret into, this resolves the
+	// targetNamespaceSelector against the live Namespace objects
+	targetNamespaces, err := r.resolveTargetNamespaces(ctx, instance)
+	if err != nil {
+		l.Error(err, "failed to resolve target namespaces")
+		if uerr := r.updateStatus(ctx, instance, err.Error(), true); uerr != nil {
+			return r.errorResult(uerr)
+		}
+		return r.errorResult(err) // Try again later, with backoff unless the selector is invalid
+	}
+
+	// the owner of the source decides which namespaces the source may be copied to
+	denied, err := r.checkExportTargets(ctx, src, targetNamespaces)
+	if err != nil {
+		l.Error(err, "failed to check the export policy of the source")
+		if uerr := r.updateStatus(ctx, instance, err.Error(), true); uerr != nil {
+			return r.errorResult(uerr)
+		}
+		return r.errorResult(err) // Try again later, with backoff unless the annotations are invalid
 	}
 
 	// sync the object into the target namespaces
-	if err := r.syncSecretToNamespaces(ctx, instance, srcSecret, instance.Spec.TargetNamespaces); err != nil {
+	denied, syncErr := r.syncSecretToNamespaces(ctx, instance, src, targetNamespaces, denied)
+	// prune the copies from namespaces that are no longer a target, for example a namespace that
+	// was removed from targetNamespaces or stopped matching the targetNamespaceSelector
+	// copies in denied namespaces are deleted whatever the prunePolicy, the source must not be there
+	// and the namespace owner does not want it there
+	pruned, err := r.pruneCopies(ctx, instance, allowedNamespaces(targetNamespaces, denied), deniedNamespaces(denied),
+		instance.GetSpec().PrunePolicy)
+	if err != nil {
+		syncErr = errors.Join(syncErr, err)
+	}
+	if len(pruned) > 0 {
+		l.Info("pruned copies that are no longer wanted", "policy", instance.GetSpec().PrunePolicy, "copies", pruned)
+	}
+	instance.GetStatus().PrunedTargets = pruned
+	if err := syncErr; err != nil {
 		l.Error(err, "failed to copy the source secret to destination namespaces")
-		if uerr := r.updateStatus(ctx, instance, fmt.Sprintf("failed to sync object: %s", err), true); uerr != nil {
+		// a template that cannot be rendered is reported with its own reason, it needs a fix of the spec or the source
+		reason := failedReason
+		var tmplErr *templateError
+		if errors.As(err, &tmplErr) {
+			reason = templateErrorReason
+		}
+		if uerr := r.updateStatusWithReason(ctx, instance, reason, fmt.Sprintf("failed to sync object: %s", err)); uerr != nil {
 			l.Error(uerr, "failed to update status after sync error")
-			return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later after 7 minutes
+			return r.errorResult(errors.Join(uerr, err)) // Try again later with backoff
 		}
-		return ctrl.Result{RequeueAfter: requeueDelay}, nil // we cannot continue, try again later
+		// transient failures are retried with backoff, conflicts and invalid specs after the max delay
+		return r.errorResult(err)
+	}
+
+	// the copies another instance won the arbitration for are left to that instance, the Conflict condition names it
+	if namespaces := conflictingNamespaces(instance); len(namespaces) > 0 {
+		msg := fmt.Sprintf("not syncing into namespaces %s, the copies are synced by older instances, see the Conflict condition",
+			strings.Join(namespaces, ","))
+		l.Info(msg)
+		if err := r.updateStatusWithReason(ctx, instance, targetConflictReason, msg); err != nil {
+			l.Error(err, "failed to update status after losing the arbitration of targets")
+			return r.errorResult(err) // Try again later with backoff
+		}
+		// No need to requeue, a change or the deletion of the winner triggers a new reconcile through the overlap watches
+		return r.successResult(instance), nil
+	}
+
+	// the allowed namespaces are synced, but we refuse to sync into the denied ones
+	if len(denied) > 0 {
+		namespaces := deniedNamespaces(denied)
+		msg := fmt.Sprintf("refusing to sync into namespaces %s: %s",
+			strings.Join(namespaces, ","), denied[namespaces[0]].message)
+		l.Info(msg)
+		if err := r.updateStatusWithReason(ctx, instance, denied[namespaces[0]].reason, msg); err != nil {
+			l.Error(err, "failed to update status after refusing targets")
+			return r.errorResult(err) // Try again later with backoff
+		}
+		// No need to requeue, changes to the source annotations or to the namespaces trigger a new reconcile
+		return r.successResult(instance), nil
 	}
 
 	// once synced, we need to update the status
-	successMessage := fmt.Sprintf("successfully synced secret %s to namespaces: %s",
-		instance.Spec.SourceName, strings.Join(instance.Spec.TargetNamespaces, ","))
+	successMessage := fmt.Sprintf("successfully synced %s %s to namespaces: %s",
+		strings.ToLower(string(src.kind)), src.GetName(), strings.Join(targetNamespaces, ","))
+	if len(targetNamespaces) == 0 {
+		successMessage = fmt.Sprintf("no target namespaces matched for %s %s",
+			strings.ToLower(string(src.kind)), src.GetName())
+	}
 	if err := r.updateStatus(ctx, instance, successMessage, false); err != nil {
 		l.Error(err, "failed to update status after successful sync")
-		return ctrl.Result{RequeueAfter: requeueDelay}, nil // Try again later after 7 minutes
+		return r.errorResult(err) // Try again later with backoff
 	}
 
 	l.Info(successMessage)
-	return ctrl.Result{}, nil
+	return r.successResult(instance), nil
+}
+
+// successResult - returns the result of a successful reconcile, the instance is only requeued
+// if it asks for a periodic re-sync with spec.refreshInterval
+func (r *SecretSyncReconciler) successResult(instance syncInstance) ctrl.Result {
+	if interval := instance.GetSpec().RefreshInterval; interval != nil && interval.Duration > 0 {
+		return ctrl.Result{RequeueAfter: interval.Duration}
+	}
+	return ctrl.Result{}
 }
 
 // addFinalizerIfNeeded adds the finalizer to the instance if it is not already present.
-func (r *SecretSyncReconciler) addFinalizerIfNeeded(ctx context.Context, instance *syncv1alpha1.SecretSync, l logr.Logger) (bool, error) {
+func (r *SecretSyncReconciler) addFinalizerIfNeeded(ctx context.Context, instance syncInstance, l logr.Logger) (bool, error) {
 	if controllerutil.ContainsFinalizer(instance, secretSyncFinalizer) {
 		return false, nil // Finalizer already present, no need to requeue
 	}
 
 	if ok := controllerutil.AddFinalizer(instance, secretSyncFinalizer); !ok {
-		l.Error(fmt.Errorf("failed to add finalizer %s to instance %s", secretSyncFinalizer, instance.Name),
+		l.Error(fmt.Errorf("failed to add finalizer %s to instance %s", secretSyncFinalizer, instance.GetName()),
 			"failed to add finalizer")
 		return false, fmt.Errorf("failed to add finalizer")
 	}
@@ -213,39 +385,41 @@ func (r *SecretSyncReconciler) addFinalizerIfNeeded(ctx context.Context, instanc
 		return false, err
 	}
 
-	l.Info("finalizer added to instance", "name", instance.Name, "namespace", instance.Namespace)
+	l.Info("finalizer added to instance", "name", instance.GetName(), "namespace", instance.GetNamespace())
 	return true, nil // Finalizer added, requeue needed
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SecretSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
-	// We create a field index for SecretSyncs based on their source secret (namespace/name).
-	// Each index entry maps a Secret's key ("<namespace>/<name>") to the list of SecretSync CRs that reference it.
+	// We create a field index for SecretSyncs based on their source object (kind/namespace/name).
+	// Each index entry maps a source's key ("<kind>/<namespace>/<name>") to the list of SecretSync CRs that reference it.
 	//
 	// we can think of this like a Go map:
 	//
 	//     map[string][]*SecretSync{
-	//         "test-source/example-secret": [ssyncCR1, ssyncCR2],
-	//         "prod/config-secret":         [ssyncCR33],
+	//         "Secret/test-source/example-secret": [ssyncCR1, ssyncCR2],
+	//         "ConfigMap/prod/ca-bundle":          [ssyncCR33],
 	//     }
 	//
-	// This allows for efficient lookup when the source Secret is created or updated.
+	// This allows for efficient lookup when the source Secret or ConfigMap is created or updated.
 	// Instead of scanning all CRs, we can directly retrieve only the relevant SecretSyncs
-	// that reference the changed Secret, and trigger reconciliation for them.
+	// that reference the changed object, and trigger reconciliation for them.
 	if err := mgr.GetFieldIndexer().IndexField(
 		context.Background(),
 		&syncv1alpha1.SecretSync{},
 		bySourceSecretIndexKey, // updated here
-		func(rawObj client.Object) []string {
-			sync := rawObj.(*syncv1alpha1.SecretSync)
-			// sync.Spec.SourceName - source secret name
-			// sync.Spec.SourceNamespace - source secret namespace
-			if sync.Spec.SourceName == "" || sync.Spec.SourceNamespace == "" {
-				return nil
-			}
-			return []string{sync.Spec.SourceName + "/" + sync.Spec.SourceNamespace}
-		},
+		indexBySource,
+	); err != nil {
+		return err
+	}
+	// The byTargetSecret index maps every copy ("<kind>/<namespace>/<name>") to the SecretSyncs that write it,
+	// it lets us find the instances that overlap on a copy and decide which one of them writes it.
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&syncv1alpha1.SecretSync{},
+		byTargetSecretIndexKey,
+		indexByTarget,
 	); err != nil {
 		return err
 	}
@@ -260,11 +434,13 @@ func (r *SecretSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		// For example:
 		// - If a SecretSync exists but the source Secret doesn't yet, this will allow us to sync it once it appears.
 		// - If a Secret is updated (e.g., its data changes), we want to propagate the changes to all target namespaces.
+		// - If a copy we manage is edited or deleted by someone else, its owner is re-queued to restore it.
 		Watches(
 			&corev1.Secret{}, // The object type to watch
 			//
 			// The mapping function: when a Secret is changed, we call this function
-			// to determine which SecretSyncs reference this Secret (via sourceName + sourceNamespace).
+			// to determine which SecretSyncs reference this Secret (via sourceName + sourceNamespace),
+			// or own it (via our ownership labels and annotations).
 			handler.EnqueueRequestsFromMapFunc(r.mapSecretToSecretSyncs),
 			//
 			// Predicate: limit the watch to only fire when a meaningful change happens to the Secret.
@@ -275,6 +451,63 @@ func (r *SecretSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			// But NOT on resyncs with no changes
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		//
+		// ConfigMap watch: the same as the Secret watch above, for SecretSyncs with sourceKind ConfigMap.
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapConfigMapToSecretSyncs),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		//
+		// Namespace watch: re-trigger reconciliation when a namespace is created, deleted or relabelled.
+		// This lets a SecretSync with a targetNamespaceSelector copy the secret into a new matching namespace
+		// right away, and remove the copy from a namespace that no longer matches.
+		Watches(
+			&corev1.Namespace{},
+			handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToSecretSyncs),
+			// only label changes can change the result of a selector and only annotation changes can change
+			// the accept-from annotation, other namespace updates are ignored
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})),
+		).
+		//
+		// Overlap watches: when an instance that shares copies with SecretSyncs is created, changed or deleted,
+		// those SecretSyncs are re-queued, the oldest instance writes the copy and the others release it.
+		Watches(
+			&syncv1alpha1.SecretSync{},
+			handler.EnqueueRequestsFromMapFunc(r.mapOverlappingToSecretSyncs),
+			builder.WithPredicates(overlapChangedPredicate),
+		).
+		Watches(
+			&syncv1alpha1.ClusterSecretSync{},
+			handler.EnqueueRequestsFromMapFunc(r.mapOverlappingToSecretSyncs),
+			builder.WithPredicates(overlapChangedPredicate),
+		).
+		// failed reconciles are retried with exponential backoff between RetryBaseDelay and RetryMaxDelay
+		WithOptions(controller.Options{RateLimiter: r.rateLimiter()}).
 		Named("secretsync"). // Give the controller a name for logs/metrics/etc.
 		Complete(r)          // Complete the controller setup
 }
+
+// indexBySource - the index function for the bySourceSecret field index,
+// it is shared by the SecretSync and the ClusterSecretSync controllers
+func indexBySource(rawObj client.Object) []string {
+	sync, ok := rawObj.(syncInstance)
+	if !ok {
+		return nil
+	}
+	// sync.GetSpec().SourceName - source secret name
+	// sync.GetSpec().SourceNamespace - source secret namespace
+	spec := sync.GetSpec()
+	if spec.SourceNamespace == "" {
+		return nil
+	}
+	var values []string
+	if spec.SourceName != "" {
+		values = append(values, sourceIndexValue(sourceKindOf(sync), spec.SourceNamespace, spec.SourceName))
+	}
+	// a SecretSync with several sources has an entry for every source, a change of any of them re-syncs the merged copy
+	for _, ref := range spec.Sources {
+		values = append(values, sourceIndexValue(sourceKindOf(sync), spec.SourceNamespaceFor(ref), ref.Name))
+	}
+	return values
+}
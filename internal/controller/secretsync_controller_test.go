@@ -21,8 +21,10 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -82,3 +84,68 @@ var _ = Describe("SecretSync Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("Adopting an existing copy", func() {
+	ctx := context.Background()
+
+	It("should keep updating an adopted secret when the source changes", func() {
+		By("creating the source, the target namespace and a copy made by hand")
+		target := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "adopt-target"}}
+		Expect(k8sClient.Create(ctx, target)).To(Succeed())
+		source := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "adopt-source",
+				Namespace:   "default",
+				Annotations: map[string]string{exportToAnnotation: "adopt-target"},
+			},
+			Data: map[string][]byte{"password": []byte("v1")},
+		}
+		Expect(k8sClient.Create(ctx, source)).To(Succeed())
+		// a plain Create, like kubectl create, leaves the data owned by another field manager
+		handMade := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopt-source", Namespace: "adopt-target"},
+			Data:       map[string][]byte{"password": []byte("v1")},
+		}
+		Expect(k8sClient.Create(ctx, handMade)).To(Succeed())
+
+		instance := &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{Name: "adopt", Namespace: "default"},
+			Spec: syncv1alpha1.SecretSyncSpec{
+				SourceName:       "adopt-source",
+				SourceNamespace:  "default",
+				TargetNamespaces: []string{"adopt-target"},
+				ConflictPolicy:   syncv1alpha1.ConflictPolicyAdopt,
+			},
+		}
+		Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, instance)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, source)).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, handMade))).To(Succeed())
+			Expect(k8sClient.Delete(ctx, target)).To(Succeed())
+		})
+
+		r := &SecretSyncReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		sync := func() (*targetWrite, error) {
+			src := newSourceObject(source)
+			data, err := desiredData(instance, src)
+			Expect(err).NotTo(HaveOccurred())
+			return r.syncSecretToNamespace(ctx, instance, src, data, contentHash(src.secretType, data), "adopt-target", false)
+		}
+
+		By("adopting the copy")
+		write, err := sync()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(write.resolution).To(Equal(syncv1alpha1.ConflictResolutionAdopted))
+
+		By("changing the source")
+		source.Data["password"] = []byte("v2")
+		Expect(k8sClient.Update(ctx, source)).To(Succeed())
+		_, err = sync()
+		Expect(err).NotTo(HaveOccurred())
+
+		adopted := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "adopt-source", Namespace: "adopt-target"}, adopted)).To(Succeed())
+		Expect(adopted.Data).To(HaveKeyWithValue("password", []byte("v2")))
+	})
+})
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// sourceObject is the kind agnostic view of the object that is copied into the target namespaces.
+// Secrets and ConfigMaps store their payload differently, so the payload is normalised into data
+// and the rest of the reconciler does not need to care which kind it is copying.
+type sourceObject struct {
+	client.Object                         // the source Secret or ConfigMap as read from the API server
+	kind          syncv1alpha1.SourceKind // the kind of the source object
+	data          map[string][]byte       // Secret data, or ConfigMap data and binaryData
+	secretType    corev1.SecretType       // the type of the source Secret, empty for ConfigMaps
+	parts         []*sourceObject         // the sources of a merged source in spec.sources order, nil for a single source
+	keySources    map[string]string       // the source, as <namespace>/<name>, every key of a merged source comes from
+}
+
+// objects - returns the objects the source was read from, the parts of a merged source or the source itself,
+// the access checks run against every one of them
+func (s *sourceObject) objects() []*sourceObject {
+	if len(s.parts) == 0 {
+		return []*sourceObject{s}
+	}
+	return s.parts
+}
+
+// sourceKindOf - returns the kind of object the instance syncs, Secret if the field is not set
+func sourceKindOf(instance syncInstance) syncv1alpha1.SourceKind {
+	return instance.GetSpec().Kind()
+}
+
+// targetNameFor - returns the name of the copy in the target namespace ns, the per namespace
+// override wins over spec.targetName, which wins over the name of the source
+func targetNameFor(instance syncInstance, ns string) string {
+	return instance.GetSpec().TargetNameFor(ns)
+}
+
+// newObjectForKind - returns an empty object of the given kind that can be used to read from the API server
+func newObjectForKind(kind syncv1alpha1.SourceKind) client.Object {
+	if kind == syncv1alpha1.SourceKindConfigMap {
+		return &corev1.ConfigMap{}
+	}
+	return &corev1.Secret{}
+}
+
+// getSourceObject - reads the source object of the instance from the API server
+func (r *SecretSyncReconciler) getSourceObject(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that called the reconcile function
+) (*sourceObject, error) {
+	if len(instance.GetSpec().Sources) > 0 {
+		return r.getMergedSource(ctx, instance)
+	}
+	kind := sourceKindOf(instance)
+	obj := newObjectForKind(kind)
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      instance.GetSpec().SourceName,
+		Namespace: instance.GetSpec().SourceNamespace,
+	}, obj); err != nil {
+		return nil, err
+	}
+	return newSourceObject(obj), nil
+}
+
+// newSourceObject - wraps a Secret or a ConfigMap into a sourceObject
+func newSourceObject(obj client.Object) *sourceObject {
+	src := &sourceObject{Object: obj, data: objectData(obj)}
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		src.kind = syncv1alpha1.SourceKindConfigMap
+	case *corev1.Secret:
+		src.kind = syncv1alpha1.SourceKindSecret
+		src.secretType = o.Type
+	}
+	return src
+}
+
+// objectData - returns the payload of a Secret or a ConfigMap as a single map
+func objectData(obj client.Object) map[string][]byte {
+	switch o := obj.(type) {
+	case *corev1.Secret:
+		return o.Data
+	case *corev1.ConfigMap:
+		data := make(map[string][]byte, len(o.Data)+len(o.BinaryData))
+		for k, v := range o.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range o.BinaryData {
+			data[k] = v
+		}
+		return data
+	}
+	return nil
+}
+
+// buildCopy - builds the copy of the source that is applied in the target namespace
+// the TypeMeta is always set because server-side apply needs the apiVersion and kind
+func buildCopy(
+	kind syncv1alpha1.SourceKind, // the kind of the copy
+	name, namespace string, // where the copy is written to
+	data map[string][]byte, // the payload of the copy
+	secretType corev1.SecretType, // the type of the copy, ignored for ConfigMaps
+) client.Object {
+	meta := metav1.ObjectMeta{Name: name, Namespace: namespace}
+
+	if kind == syncv1alpha1.SourceKindConfigMap {
+		cm := &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: string(syncv1alpha1.SourceKindConfigMap)},
+			ObjectMeta: meta,
+		}
+		// ConfigMaps keep UTF-8 values in data and everything else in binaryData
+		for k, v := range data {
+			if utf8.Valid(v) {
+				if cm.Data == nil {
+					cm.Data = make(map[string]string, len(data))
+				}
+				cm.Data[k] = string(v)
+				continue
+			}
+			if cm.BinaryData == nil {
+				cm.BinaryData = make(map[string][]byte)
+			}
+			cm.BinaryData[k] = v
+		}
+		return cm
+	}
+
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: string(syncv1alpha1.SourceKindSecret)},
+		ObjectMeta: meta,
+		Data:       data,
+		Type:       secretType,
+	}
+}
+
+// secretTypeOf - returns the type of a Secret, empty for ConfigMaps
+func secretTypeOf(obj client.Object) corev1.SecretType {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		return secret.Type
+	}
+	return ""
+}
+
+// kindOfObject - returns the kind of a Secret or a ConfigMap, objects read from the cache
+// do not always carry their TypeMeta so we cannot rely on GetObjectKind
+func kindOfObject(obj client.Object) syncv1alpha1.SourceKind {
+	if _, ok := obj.(*corev1.ConfigMap); ok {
+		return syncv1alpha1.SourceKindConfigMap
+	}
+	return syncv1alpha1.SourceKindSecret
+}
+
+// sourceIndexValue - returns the value stored in the bySourceSecret index for a source object
+func sourceIndexValue(kind syncv1alpha1.SourceKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+}
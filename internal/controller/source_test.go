@@ -0,0 +1,59 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("targetNameFor", func() {
+	It("should prefer the namespace override, then targetName, then the source name", func() {
+		instance := &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{SourceName: "my-secret"}}
+		Expect(targetNameFor(instance, "team-a")).To(Equal("my-secret"))
+
+		instance.Spec.TargetName = "shared-secret"
+		instance.Spec.TargetNameOverrides = map[string]string{"team-b": "my-app-secret"}
+		Expect(targetNameFor(instance, "team-a")).To(Equal("shared-secret"))
+		Expect(targetNameFor(instance, "team-b")).To(Equal("my-app-secret"))
+	})
+})
+
+var _ = Describe("ConfigMap sources", func() {
+	binary := []byte{0xff, 0xfe, 0x00}
+
+	It("should read data and binaryData of a ConfigMap as one map", func() {
+		src := newSourceObject(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+			Data:       map[string]string{"app.yaml": "port: 80"},
+			BinaryData: map[string][]byte{"logo.png": binary},
+		})
+		Expect(src.kind).To(Equal(syncv1alpha1.SourceKindConfigMap))
+		Expect(src.secretType).To(BeEmpty())
+		Expect(src.data).To(Equal(map[string][]byte{"app.yaml": []byte("port: 80"), "logo.png": binary}))
+	})
+
+	It("should write UTF-8 values to data and everything else to binaryData", func() {
+		obj := buildCopy(syncv1alpha1.SourceKindConfigMap, "settings", "team-a",
+			map[string][]byte{"app.yaml": []byte("port: 80"), "logo.png": binary}, corev1.SecretTypeOpaque)
+		cm, ok := obj.(*corev1.ConfigMap)
+		Expect(ok).To(BeTrue())
+		Expect(cm.Kind).To(Equal("ConfigMap"))
+		Expect(cm.Namespace).To(Equal("team-a"))
+		Expect(cm.Data).To(Equal(map[string]string{"app.yaml": "port: 80"}))
+		Expect(cm.BinaryData).To(Equal(map[string][]byte{"logo.png": binary}))
+		Expect(objectData(cm)).To(HaveLen(2))
+	})
+
+	It("should build a Secret of the type of the source", func() {
+		obj := buildCopy(syncv1alpha1.SourceKindSecret, "tls", "team-a",
+			map[string][]byte{"tls.crt": []byte("crt")}, corev1.SecretTypeTLS)
+		secret, ok := obj.(*corev1.Secret)
+		Expect(ok).To(BeTrue())
+		Expect(secret.Kind).To(Equal("Secret"))
+		Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+		Expect(objectData(secret)).To(Equal(map[string][]byte{"tls.crt": []byte("crt")}))
+	})
+})
@@ -6,51 +6,139 @@ import (
 	"strings"
 
 	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	successReason = "SecretSyncedSuccessfully"
-	failedReason  = "SecretSyncFailed"
+	successReason           = "SecretSyncedSuccessfully"
+	failedReason            = "SecretSyncFailed"
+	sourceNotExportedReason = "SourceNotExported" // a namespaced SecretSync reads a source that is not exported
+	exportNotAllowedReason  = "ExportNotAllowed"  // the source does not allow to be copied to some of the targets
+	importNotAcceptedReason = "ImportNotAccepted" // some of the target namespaces do not accept the copy
+	templateErrorReason     = "TemplateError"     // spec.template cannot be rendered against the source
+	targetConflictReason    = "TargetConflict"    // some of the copies are synced by older instances
+
+	syncedConditionType    = "Synced"            // the result of the last sync
+	conflictConditionType  = "Conflict"          // True while older instances sync some of the copies
+	olderInstanceReason    = "OlderInstanceWins" // the reason of the Conflict condition
+	suspendedConditionType = "Suspended"         // True while spec.suspend pauses the sync
+	suspendedReason        = "SuspendedBySpec"   // the reason of the Suspended condition
 )
 
 // updateStatus - udpates the status of the CR object
 func (r *SecretSyncReconciler) updateStatus(
 	ctx context.Context, // context for the API call
-	instance *syncv1alpha1.SecretSync, // the CR that needs to be updated
+	instance syncInstance, // the CR that needs to be updated
 	message string, // message on the status field
 	isError bool, // if true, the status will be set to "Error", otherwise "Success"
+) error {
+	reason := successReason
+	if isError {
+		reason = failedReason
+	}
+	return r.updateStatusWithReason(ctx, instance, reason, message)
+}
+
+// updateStatusWithReason - udpates the status of the CR object with a specific reason on the Synced condition
+// the condition is True only for successReason, every other reason describes why the sync failed
+func (r *SecretSyncReconciler) updateStatusWithReason(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that needs to be updated
+	reason string, // reason on the status field
+	message string, // message on the status field
 ) error {
 	// we set some fields to reflect that current state of the CR
-	instance.Status.LastSyncTime = metav1.Now()
+	instance.GetStatus().LastSyncTime = metav1.Now()
 
 	// Set condition
 	condition := metav1.Condition{
-		Type:               "Synced", // type of the condition
+		Type:               syncedConditionType, // type of the condition
 		LastTransitionTime: metav1.Now(),
-		ObservedGeneration: instance.Generation,
+		ObservedGeneration: instance.GetGeneration(),
 		Message:            message,
 		Status:             metav1.ConditionTrue,
-		Reason:             successReason,
+		Reason:             reason,
 	}
 	// if there is an error, we set the status to "False"
-	if isError {
+	if reason != successReason {
 		condition.Status = metav1.ConditionFalse
-		condition.Reason = failedReason
 	}
 
-	// we are not appending the condition, we are replacing it
-	// this is because we want to have only one condition of type "Synced" at a time
-	instance.Status.Conditions = []metav1.Condition{condition}
+	// we are not appending the condition, we are replacing the condition of the same type
+	// this is because we want to have only one condition of type "Synced" at a time,
+	// the Conflict condition is kept in line with status.targets next to it
+	meta.SetStatusCondition(&instance.GetStatus().Conditions, condition)
+	setConflictCondition(instance)
+	setSuspendedCondition(instance)
+	return r.Status().Update(ctx, instance)
+}
+
+// updateSuspendedStatus - reports that the instance is suspended, the Synced condition and status.targets
+// are left as they were after the last sync, so they still describe what the copies hold
+// the status is only written when the condition changes, a suspended instance makes no writes at all
+func (r *SecretSyncReconciler) updateSuspendedStatus(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the suspended CR
+) error {
+	if !setSuspendedCondition(instance) {
+		return nil
+	}
+	r.recordEvent(instance, corev1.EventTypeNormal, eventReasonSuspended,
+		"sync is suspended, copies are neither written nor pruned until spec.suspend is cleared")
 	return r.Status().Update(ctx, instance)
 }
 
-// checkSourceInTargetNamespaces checks if the source namespace is part of the target namespaces
-func checkSourceInTargetNamespaces(instance *syncv1alpha1.SecretSync) error {
-	for _, ns := range instance.Spec.TargetNamespaces {
-		if ns == instance.Spec.SourceNamespace {
+// setSuspendedCondition - sets the Suspended condition from spec.suspend, it is True while the sync is paused
+// and it is removed once the sync resumes, it returns true if the conditions changed
+func setSuspendedCondition(instance syncInstance) bool {
+	if !instance.GetSpec().Suspend {
+		return meta.RemoveStatusCondition(&instance.GetStatus().Conditions, suspendedConditionType)
+	}
+	return meta.SetStatusCondition(&instance.GetStatus().Conditions, metav1.Condition{
+		Type:               suspendedConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.GetGeneration(),
+		Reason:             suspendedReason,
+		Message:            "spec.suspend is true, copies are neither written nor pruned",
+	})
+}
+
+// setConflictCondition - sets the Conflict condition from status.targets, it is True and names the winners
+// while older instances sync some of the copies, and it is removed once the instance syncs all of them
+func setConflictCondition(instance syncInstance) {
+	var messages []string
+	for _, target := range instance.GetStatus().Targets {
+		if target.Result == syncv1alpha1.TargetResultConflict {
+			messages = append(messages, target.LastError)
+		}
+	}
+	if len(messages) == 0 {
+		meta.RemoveStatusCondition(&instance.GetStatus().Conditions, conflictConditionType)
+		return
+	}
+	meta.SetStatusCondition(&instance.GetStatus().Conditions, metav1.Condition{
+		Type:               conflictConditionType,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: instance.GetGeneration(),
+		Reason:             olderInstanceReason,
+		Message:            strings.Join(messages, "; "),
+	})
+}
+
+// checkSourceInTargetNamespaces checks if the source namespace, or the namespace of an entry of spec.sources,
+// is part of the target namespaces
+func checkSourceInTargetNamespaces(instance syncInstance) error {
+	spec := instance.GetSpec()
+	for _, ns := range spec.TargetNamespaces {
+		if ns == spec.SourceNamespace {
 			return fmt.Errorf("the sourceNamespace %s is in the targetNamespaces list %s, please remove this",
-				ns, strings.Join(instance.Spec.TargetNamespaces, ","))
+				ns, strings.Join(spec.TargetNamespaces, ","))
+		}
+		if spec.IsSourceNamespace(ns) {
+			return fmt.Errorf("the namespace %s of spec.sources is in the targetNamespaces list %s, please remove this",
+				ns, strings.Join(spec.TargetNamespaces, ","))
 		}
 	}
 	return nil
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("setSuspendedCondition", func() {
+	It("should follow spec.suspend and report only changes", func() {
+		instance := &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{Suspend: true}}
+		Expect(setSuspendedCondition(instance)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(instance.Status.Conditions, suspendedConditionType)).To(BeTrue())
+		Expect(setSuspendedCondition(instance)).To(BeFalse())
+
+		instance.Spec.Suspend = false
+		Expect(setSuspendedCondition(instance)).To(BeTrue())
+		Expect(meta.FindStatusCondition(instance.Status.Conditions, suspendedConditionType)).To(BeNil())
+	})
+})
+
+var _ = Describe("checkSourceInTargetNamespaces", func() {
+	It("should refuse the namespace of every source as a target", func() {
+		instance := &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{
+			SourceNamespace:  "default",
+			TargetName:       "app",
+			Sources:          []syncv1alpha1.SourceRef{{Name: "db"}, {Name: "cache", Namespace: "platform"}},
+			TargetNamespaces: []string{"team-a"},
+		}}
+		Expect(checkSourceInTargetNamespaces(instance)).To(Succeed())
+
+		instance.Spec.TargetNamespaces = append(instance.Spec.TargetNamespaces, "platform")
+		Expect(checkSourceInTargetNamespaces(instance)).To(MatchError(ContainSubstring("the namespace platform of spec.sources")))
+
+		instance.Spec.TargetNamespaces = []string{"default"}
+		Expect(checkSourceInTargetNamespaces(instance)).To(MatchError(ContainSubstring("the sourceNamespace default")))
+	})
+})
@@ -4,150 +4,410 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 
 	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// syncSecretToNamespaces - copies src secret to the dst namespaces
+// syncSecretToNamespaces - copies src secret or configmap to the dst namespaces
+// the result for each namespace is recorded in status.targets of the instance
+// the denied namespaces are not written to and are reported with the Denied result
+// before writing into a namespace we also check that the namespace accepts the copy,
+// the returned map holds every denied namespace including the ones that did not accept the copy
 func (r *SecretSyncReconciler) syncSecretToNamespaces(
 	ctx context.Context,
-	instance *syncv1alpha1.SecretSync,
-	srcSecret *corev1.Secret, // the source secret object
-	dstNamespaces []string) error {
-	// set the ower reference for the source object
+	instance syncInstance,
+	src *sourceObject, // the source secret or configmap
+	dstNamespaces []string,
+	denied map[string]targetDenial, // the namespaces that may not receive a copy
+) (map[string]targetDenial, error) {
 
-	var combineErr error
+	// we keep the previous status of every target so that a failed sync does not
+	// lose the time and the source revision of the last successful sync
+	previous := make(map[string]syncv1alpha1.TargetStatus, len(instance.GetStatus().Targets))
+	for _, target := range instance.GetStatus().Targets {
+		previous[target.Namespace] = target
+	}
+
+	targets := make([]syncv1alpha1.TargetStatus, 0, len(dstNamespaces))
+
+	// only the selected and rendered keys are copied, an invalid spec.data or spec.template fails every target
+	// a spec that cannot be applied to the source is not retried hot
+	data, dataErr := desiredData(instance, src)
+	dataErr = permanent(dataErr)
+	hash := contentHash(src.secretType, data)
 
-	// get the type of the object that needs to be copied
+	// a new value of the reconcile-at annotation re-applies every copy, even the ones that look up to date
+	reconcileRequest, force := pendingReconcileRequest(instance)
+
+	allDenied := maps.Clone(denied)
+	if allDenied == nil {
+		allDenied = make(map[string]targetDenial)
+	}
+
+	var combineErr error
 	for _, ns := range dstNamespaces {
+		target := previous[ns]
+		target.Namespace = ns
+		target.Name = targetNameFor(instance, ns)
 
-		// copy := srcObj.DeepCopyObject()
-		copySecret := &corev1.Secret{
-			TypeMeta: metav1.TypeMeta{
-				APIVersion: srcSecret.APIVersion,
-				Kind:       srcSecret.Kind,
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      srcSecret.Name,
-				Namespace: ns,
-			},
-			Data: srcSecret.Data,
-			Type: srcSecret.Type,
+		// the owner of the target namespace decides who can write into it
+		if _, ok := allDenied[ns]; !ok {
+			denial, err := r.checkImportConsent(ctx, instance, src, ns)
+			if err != nil {
+				combineErr = errors.Join(combineErr, err)
+				target.Result = syncv1alpha1.TargetResultFailed
+				target.LastError = err.Error()
+				recordSyncMetric(instance, ns, target.Result)
+				targets = append(targets, target)
+				continue
+			}
+			if denial != nil {
+				allDenied[ns] = *denial
+			}
 		}
 
-		// we cannot set the owner reference here because the object is being copied to a different namespace
-		// and the owner reference is not allowed to be set across namespaces
-		// we can set the owner reference only if the object is in the same namespace as the owner object
-		// if err := controllerutil.SetControllerReference(instance, copy, r.Scheme); err != nil {
-		// 	combineErr = errors.Join(err)
-		// 	continue
-		// }
-		// so the above snippet does not work
-		//
-		// before we copy the object, we need to check if the secret exists in the target namespace
-		if err := r.checkIfSecretAlreadyExistsAndNotOwned(ctx, instance, ns); err != nil {
-			// if the secret already exists in the target namespace and is not owned by this CR,
-			// we need to return an error and not copy the secret object
-			// but we need to continue the loop so that we can check the next namespace
+		if denial, ok := allDenied[ns]; ok {
+			target.Result = syncv1alpha1.TargetResultDenied
+			target.LastError = denial.message
+			targets = append(targets, target)
+			continue
+		}
+
+		// instances that write the same copy are arbitrated, the oldest one writes it
+		// and the others leave the copy alone until the winner releases it
+		contenders, err := r.targetContenders(ctx, instance, ns, target.Name)
+		if err != nil {
 			combineErr = errors.Join(combineErr, err)
+			target.Result = syncv1alpha1.TargetResultFailed
+			target.LastError = err.Error()
+			recordSyncMetric(instance, ns, target.Result)
+			targets = append(targets, target)
+			continue
+		}
+		if winner := contenders[0]; winner.GetUID() != instance.GetUID() {
+			msg := fmt.Sprintf("the %s %s in namespace %s is synced by %s, which was created first",
+				src.kind, target.Name, ns, describeInstance(winner))
+			// the conflict is only reported once, it lasts until the winner goes away
+			if target.Result != syncv1alpha1.TargetResultConflict {
+				r.recordEvent(instance, corev1.EventTypeWarning, eventReasonConflict, "%s", msg)
+			}
+			target.Result = syncv1alpha1.TargetResultConflict
+			target.LastError = msg
+			targets = append(targets, target)
+			continue
+		}
+
+		if dataErr != nil {
+			target.Result = syncv1alpha1.TargetResultFailed
+			target.LastError = dataErr.Error()
+			recordSyncMetric(instance, ns, target.Result)
+			targets = append(targets, target)
 			continue
 		}
 
-		// we need to see the correct annotations and labels that we need to add to the copied object
-		// we will add the controller name, owner name and owner namespace to the annotations
-		// we will also add the controller name to the labels
-		// to the copy object
-		annotations := make(map[string]string, annotationsLen)
-		annotations[controllerNameKey] = controllerNameValue
-		annotations[controllerOwnerNameKey] = instance.Name
-		annotations[controllerOwnerNamespacekey] = instance.Namespace
-
-		// we are setting both labels and annotations to the copied object
-		// this is because we want to be able to filter the objects based on the labels
-		// and also want to be able to find the owner of the object based on the annotations
-		// this is useful when we want to delete the object later
-		// for example, if we want to delete the object later, we can filter the objects based on the labels
-		// and then delete the objects that have the same owner name
-		// and owner namespace as the instance
-		// this way we can delete all the objects that are owned by this instance
-		// we can also use the annotations to find the owner of the object
-		copySecret.SetLabels(annotations)
-		copySecret.SetAnnotations(annotations)
-		patchErr := r.Patch(ctx, copySecret, client.Apply, client.FieldOwner(controllerNameValue))
-		combineErr = errors.Join(patchErr)
-	}
-
-	return combineErr
+		write, err := r.syncSecretToNamespace(ctx, instance, src, data, hash, ns, force)
+		if err != nil {
+			// we need to continue the loop so that we can sync the next namespace
+			combineErr = errors.Join(combineErr, err)
+			target.Result = syncv1alpha1.TargetResultFailed
+			target.LastError = err.Error()
+			recordSyncMetric(instance, ns, target.Result)
+		} else {
+			if write.resolution != "" {
+				target.ConflictResolution = write.resolution
+			}
+			// we only report the copies whose content changed, so that an unrelated reconcile
+			// does not flood the event stream
+			if target.Result != syncv1alpha1.TargetResultSynced || target.SourceHash != hash {
+				r.recordTargetEvent(instance, write.copy, corev1.EventTypeNormal, eventReasonSynced,
+					"synced %s %s/%s to %s/%s", src.kind, src.GetNamespace(), src.GetName(), ns, write.copy.GetName())
+			} else if drift := detectDrift(write.existing, hash); drift != "" {
+				// the copy was in sync after the last write and the desired content did not change since,
+				// so someone else changed or deleted it and we have just put it back
+				r.recordTargetEvent(instance, write.copy, corev1.EventTypeWarning, eventReasonDriftCorrected,
+					"%s %s in namespace %s was %s outside of the controller, restored it from %s %s/%s",
+					src.kind, write.copy.GetName(), ns, drift, src.kind, src.GetNamespace(), src.GetName())
+				recordDriftMetric(instance, ns)
+			}
+			// the latency is only observed when a change of the source reaches a copy that already existed,
+			// a new target would otherwise report the age of the source
+			if !write.skipped && target.SourceResourceVersion != "" && target.SourceResourceVersion != src.GetResourceVersion() {
+				recordPropagationLatency(instance, src)
+			}
+			// the workloads that consume the copy are restarted once its content changed, the hash of the
+			// last successful sync is only updated after the restart so that a failed restart is retried
+			if target.SourceHash != "" && target.SourceHash != hash {
+				if err := r.restartWorkloads(ctx, instance, write.copy); err != nil {
+					combineErr = errors.Join(combineErr, err)
+					target.Result = syncv1alpha1.TargetResultFailed
+					target.LastError = err.Error()
+					recordSyncMetric(instance, ns, target.Result)
+					targets = append(targets, target)
+					continue
+				}
+			}
+			// a copy that was already up to date, for example on the reconcile our own write triggered,
+			// is not counted as a sync
+			if !write.skipped {
+				recordSyncMetric(instance, ns, syncv1alpha1.TargetResultSynced)
+			}
+			now := metav1.Now()
+			target.Result = syncv1alpha1.TargetResultSynced
+			target.LastSyncTime = &now
+			target.SourceResourceVersion = src.GetResourceVersion()
+			target.SourceHash = hash
+			target.LastError = ""
+		}
+		targets = append(targets, target)
+	}
+
+	instance.GetStatus().Targets = targets
+	if dataErr == nil {
+		instance.GetStatus().SourceHash = hash
+		instance.GetStatus().KeySources = keySourcesFor(instance, src)
+	}
+	// the request is only handled once every target was written, otherwise the next attempt
+	// re-applies every copy again, denied targets and conflicts are not retried by a force
+	if force && dataErr == nil && combineErr == nil {
+		instance.GetStatus().LastHandledReconcileAt = reconcileRequest
+	}
+	recordTargetMetrics(instance, targets)
+	return allDenied, errors.Join(dataErr, combineErr)
+}
+
+// targetWrite describes a successful write of a copy into a target namespace
+type targetWrite struct {
+	copy       client.Object                   // the copy as it was written
+	existing   client.Object                   // the object as it was before the write, nil if it did not exist
+	resolution syncv1alpha1.ConflictResolution // how an existing object was taken over, if it was
+	skipped    bool                            // the copy was already up to date and was not written
+}
+
+// syncSecretToNamespace - copies src secret or configmap to a single dst namespace
+func (r *SecretSyncReconciler) syncSecretToNamespace(
+	ctx context.Context,
+	instance syncInstance,
+	src *sourceObject, // the source secret or configmap
+	data map[string][]byte, // the selected data of the source
+	hash string, // the hash of data and the type of the source, see contentHash
+	ns string, // the namespace the secret is copied to
+	force bool, // write the copy even if it is up to date
+) (*targetWrite, error) {
+	// the copy is of the same kind as the source and is written under the resolved target name
+	copySecret := buildCopy(src.kind, targetNameFor(instance, ns), ns, data, src.secretType)
+
+	// we cannot set the owner reference here because the object is being copied to a different namespace
+	// and the owner reference is not allowed to be set across namespaces
+	// we can set the owner reference only if the object is in the same namespace as the owner object
+	// if err := controllerutil.SetControllerReference(instance, copy, r.Scheme); err != nil {
+	// 	combineErr = errors.Join(err)
+	// 	continue
+	// }
+	// so the above snippet does not work
+	//
+	// before we copy the object, we need to check if the secret exists in the target namespace
+	existing, resolution, err := r.checkIfSecretAlreadyExistsAndNotOwned(ctx, instance, copySecret)
+	if err != nil {
+		// if the secret already exists in the target namespace and is not owned by this CR,
+		// we need to return an error and not copy the secret object
+		return nil, err
+	}
+
+	// the labels identify the owner by its uid, this is what we use to list and delete the copies later
+	// the annotations carry the name and namespace of the owner so that humans can find it
+	// besides our own keys, spec.metadata can copy labels and annotations of the source and add static ones
+	var labelRules, annotationRules *syncv1alpha1.MetadataRules
+	if metadata := instance.GetSpec().Metadata; metadata != nil {
+		labelRules, annotationRules = metadata.Labels, metadata.Annotations
+	}
+	labels := desiredMetadata(labelRules, src.GetLabels(), ownershipLabels(instance))
+	annotations := desiredMetadata(annotationRules, src.GetAnnotations(), ownershipAnnotations(instance))
+	// the copy records what it holds, so that its content can be traced back to the source from the target side
+	// the metadata hash covers everything but the resourceVersion of the source, which changes on every update
+	// of the source even if the content of the copy does not
+	annotations[sourceHashAnnotation] = hash
+	annotations[sourceUIDAnnotation] = string(src.GetUID())
+	annotations[metadataHashAnnotation] = metadataHash(labels, annotations)
+	annotations[sourceResourceVersionAnnotation] = src.GetResourceVersion()
+	copySecret.SetLabels(labels)
+	copySecret.SetAnnotations(annotations)
+
+	// nothing to write if the copy already holds this content and metadata, this saves an API call
+	// for every target on every reconcile
+	if !force && resolution == "" && isUpToDate(existing, copySecret) {
+		return &targetWrite{copy: existing, existing: existing, skipped: true}, nil
+	}
+
+	patchOptions := []client.PatchOption{client.FieldOwner(controllerNameValue)}
+	if resolution != "" {
+		// the fields of the existing object are owned by other managers, for example kubectl, we take them over,
+		// otherwise the next change of the source would fail with a conflict on the fields they still own
+		// an adopted object already holds the data of the source, so forcing loses nothing
+		patchOptions = append(patchOptions, client.ForceOwnership)
+	}
+	if err := r.Patch(ctx, copySecret, client.Apply, patchOptions...); err != nil {
+		err = fmt.Errorf("error applying %s %s in namespace %s: %w", src.kind, copySecret.GetName(), ns, err)
+		r.recordEvent(instance, corev1.EventTypeWarning, eventReasonSyncFailed, "%s", err)
+		return nil, err
+	}
+
+	switch resolution {
+	case syncv1alpha1.ConflictResolutionAdopted:
+		r.recordTargetEvent(instance, copySecret, corev1.EventTypeNormal, eventReasonAdopted,
+			"adopted existing %s %s in namespace %s, its data matches the source", src.kind, copySecret.GetName(), ns)
+	case syncv1alpha1.ConflictResolutionOverwritten:
+		r.recordTargetEvent(instance, copySecret, corev1.EventTypeNormal, eventReasonOverwritten,
+			"took over existing %s %s in namespace %s and replaced its data", src.kind, copySecret.GetName(), ns)
+	}
+	return &targetWrite{copy: copySecret, existing: existing, resolution: resolution}, nil
 }
 
 // checkIfSecretAlreadyExists checks if the secret or configmap already exists in the target namespace
-// if this is the case, we need to check the annotations on the child object i.e the secret or configmap
-// based on the annotations, we can decide if the object is owned by this CR or not
+// if this is the case, we need to check the owner-uid label on the child object i.e the secret or configmap
+// based on the label, we can decide if the object is owned by this CR or not
 // If the object is owned by this CR, we can continue
+// If the object is not managed by the controller at all, spec.conflictPolicy decides if we take it over,
+// the returned resolution tells the caller how it was taken over
 // If the object is not owned by this CR, we return an error
-// this error exists because we are copying the secret as it is and not generating a new name for the secret
-// for example, if the source secret is called foo then the destiation secret will also be called foo but
-// will be in a different namespace
+// the existing object is returned as well, nil if it does not exist
+// this error exists because the name of the copy is fixed, it is the name of the source secret
+// unless spec.targetName or spec.targetNameOverrides is set, for example if the source secret is called foo
+// then the destination secret will also be called foo but will be in a different namespace
 func (r *SecretSyncReconciler) checkIfSecretAlreadyExistsAndNotOwned(
 	ctx context.Context, // context for the API call
-	instance *syncv1alpha1.SecretSync, // the CR that called the reconcile function
-	ns string, // the namespace where we want to check if the secret already exists
-) error {
+	instance syncInstance, // the CR that called the reconcile function
+	copySecret client.Object, // the copy we want to write, its name and namespace are checked
+) (client.Object, syncv1alpha1.ConflictResolution, error) {
 
-	var secret corev1.Secret // this is where we will store the secret object we read from
+	ns := copySecret.GetNamespace()
+	// this is where we will store the secret or configmap object we read from
+	secret := newObjectForKind(kindOfObject(copySecret))
 
 	// read the object from our local cache to see if already exists
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      instance.Spec.SourceName, // the name of the secret
-		Namespace: ns,                       // the namespace where we want to check
-	}, &secret)
+	err := r.Get(ctx, client.ObjectKeyFromObject(copySecret), secret)
 
 	if err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			// if the object is not found, we can continue
-			return nil // this means that the object does not exist in the target namespace
+			return nil, "", nil // this means that the object does not exist in the target namespace
+		}
+		return nil, "", fmt.Errorf("error reading object %s in namespace %s: %w", copySecret.GetName(), ns, err)
+	}
+	// every refusal below is recorded on the instance and on the existing object, so that the owner
+	// of the existing object can see who tries to write it
+	conflict := func(err error) (client.Object, syncv1alpha1.ConflictResolution, error) {
+		r.recordTargetEvent(instance, secret, corev1.EventTypeWarning, eventReasonConflict, "%s", err)
+		recordConflictMetric(instance, ns)
+		// the object stays in the way until someone removes it or changes the conflict policy
+		return secret, "", permanent(err)
+	}
+
+	// so the object already exists in the target namespace, it is ours only if it carries our uid,
+	// the name and namespace of the owner are not enough because a SecretSync that is deleted and
+	// created again with the same name is a different owner
+	if isOwnedBy(secret, instance) {
+		return secret, "", nil // this means that the object is owned by this instance and we can continue
+	}
+	// an object owned by another instance is only taken over from an instance that lost the arbitration of
+	// the copy to this one, see targetContenders, otherwise the two instances would fight over it
+	// this includes copies written before the uid label existed, their owner is named in the legacy labels
+	if isManaged(secret) {
+		contenders, err := r.targetContenders(ctx, instance, ns, copySecret.GetName())
+		if err != nil {
+			return nil, "", err
+		}
+		if contenders[0].GetUID() == instance.GetUID() && slices.ContainsFunc(contenders[1:], func(c syncInstance) bool {
+			return isOwnedBy(secret, c)
+		}) {
+			return secret, "", nil
+		}
+		labels, annots := secret.GetLabels(), secret.GetAnnotations()
+		owner := fmt.Sprintf("%s/%s (uid %s)",
+			annots[controllerOwnerNamespacekey], annots[controllerOwnerNameKey], labels[controllerOwnerUIDKey])
+		if labels[controllerOwnerUIDKey] == "" {
+			owner = fmt.Sprintf("%s/%s", labels[controllerOwnerNamespacekey], labels[controllerOwnerNameKey])
+		}
+		return conflict(fmt.Errorf("the %s %s already exists in namespace %s and is owned by %s, not by this instance %s",
+			kindOfObject(secret), copySecret.GetName(), ns, owner, instance.GetName()))
+	}
+	// it might be a manually created object or an object created by another tool
+	switch instance.GetSpec().ConflictPolicy {
+	case syncv1alpha1.ConflictPolicyOverwrite:
+		return secret, syncv1alpha1.ConflictResolutionOverwritten, nil
+	case syncv1alpha1.ConflictPolicyAdopt:
+		// we only adopt an object if taking it over does not change what its consumers see
+		existingHash := contentHash(secretTypeOf(secret), objectData(secret))
+		if existingHash == contentHash(secretTypeOf(copySecret), objectData(copySecret)) {
+			return secret, syncv1alpha1.ConflictResolutionAdopted, nil
 		}
-		return fmt.Errorf("error reading object %s in namespace %s: %w", instance.Spec.SourceName, ns, err)
-	}
-	// so the object already exists in the target namespace
-	// we need to check if the object is owned by this CR or not
-	// we will check the annotations on the secret to see if it is owned by this CR or not
-	// if the object is owned by this CR, we can continue
-	// if the object is not owned by this CR, we need to return an error
-	annots := secret.GetAnnotations()
-	// if the object has no annotations, we need to return an error
-	// this is because we cannot determine if the object is owned by this CR or not
-	if annots == nil {
-		// if there are no annotations, we can continue
-		return fmt.Errorf("the secret %s already exists in namespace %s but has no"+
-			" annotations, please check if this is owned by this CR", instance.Spec.SourceName, ns)
-	}
-	// if the object is not owned by this CR, we need to return an error as we cannot copy the object
-	// it might be owned by another CR or it might be a manually created object
-	if val, ok := annots[controllerNameKey]; ok && val != controllerNameValue {
-		return fmt.Errorf("the secret %s already exists in namespace %s and is not owned by this CR, "+
-			"please check if this is owned by this CR", instance.Spec.SourceName, ns)
-	}
-	// at this stage, we know that the object is owned by an instance of this controller
-	// but we need to check if the object is owned by this particular instance of the controller
-	// we will check the annotations on the secret to see if it is owned by this instance or not
-	// if the object is NOT owned by this particular instance, we need to return an error
-	if val, ok := annots[controllerOwnerNameKey]; ok && val != instance.Name {
-		return fmt.Errorf("the secret %s already exists in namespace %s and is not owned by this instance %s",
-			instance.Spec.SourceName, ns, instance.Name)
-	}
-	// Finally we also check if the namespace of the owner is the same as the instance namespace
-	// if the namespace of the owner is not the same as the instance namespace, we
-	// need to return an error as we cannot copy the object
-	// this is because the owner namespace is not the same as the instance namespace
-	if val, ok := annots[controllerOwnerNamespacekey]; ok && val != instance.Namespace {
-		return fmt.Errorf("the secret %s already exists in namespace %s and is not owned by this instance %s, "+
-			"please check if this is owned by this instance", instance.Spec.SourceName, ns, instance.Name)
-	}
-
-	return nil // this means that the object is owned by this instance and we can continue
+		return conflict(fmt.Errorf("the %s %s already exists in namespace %s and is not managed by %s, "+
+			"it cannot be adopted because its data does not match the source",
+			kindOfObject(secret), copySecret.GetName(), ns, controllerNameValue))
+	default:
+		return conflict(fmt.Errorf("the %s %s already exists in namespace %s and is not managed by %s, "+
+			"please check who owns it or set spec.conflictPolicy to Adopt or Overwrite to take it over",
+			kindOfObject(secret), copySecret.GetName(), ns, controllerNameValue))
+	}
+}
+
+// pendingReconcileRequest - returns the value of the reconcile-at annotation of the instance and true
+// if it was not handled yet, users set the annotation to a new value, for example a timestamp, to push a sync
+func pendingReconcileRequest(instance syncInstance) (string, bool) {
+	request := instance.GetAnnotations()[reconcileAtAnnotation]
+	return request, request != "" && request != instance.GetStatus().LastHandledReconcileAt
+}
+
+// detectDrift - returns how the copy drifted from the content we wrote last time, "deleted" or "modified",
+// or an empty string if it did not drift
+func detectDrift(
+	existing client.Object, // the copy before the write, nil if it did not exist
+	hash string, // the hash of the content we wrote last time
+) string {
+	if existing == nil {
+		return "deleted"
+	}
+	if contentHash(secretTypeOf(existing), objectData(existing)) != hash {
+		return "modified"
+	}
+	return ""
+}
+
+// isUpToDate - returns true if the existing copy already holds the content and the metadata of the desired copy
+// the hash annotations tell us what we wrote last time, the data and the metadata are checked as well
+// because someone else may have changed them since
+// a new revision of the source is written even if it does not change the content of the copy
+func isUpToDate(
+	existing client.Object, // the copy in the target namespace, nil if it does not exist
+	desired client.Object, // the copy we want to write
+) bool {
+	if existing == nil {
+		return false
+	}
+	existingAnnotations := existing.GetAnnotations()
+	desiredAnnotations := desired.GetAnnotations()
+	if existingAnnotations[sourceHashAnnotation] != desiredAnnotations[sourceHashAnnotation] ||
+		existingAnnotations[metadataHashAnnotation] != desiredAnnotations[metadataHashAnnotation] {
+		return false
+	}
+	if contentHash(secretTypeOf(existing), objectData(existing)) != desiredAnnotations[sourceHashAnnotation] {
+		return false
+	}
+	for k, v := range desired.GetLabels() {
+		if existing.GetLabels()[k] != v {
+			return false
+		}
+	}
+	for k, v := range desiredAnnotations {
+		// this includes the resourceVersion of the source, so that the annotation always names the
+		// revision of the source the copy was last synced from, even if the content did not change
+		if existingAnnotations[k] != v {
+			return false
+		}
+	}
+	return true
 }
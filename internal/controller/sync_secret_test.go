@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("isUpToDate", func() {
+	data := map[string][]byte{"password": []byte("s3cret")}
+	hash := contentHash(corev1.SecretTypeOpaque, data)
+
+	// newCopy returns a copy as syncSecretToNamespace builds it
+	newCopy := func(resourceVersion string) *corev1.Secret {
+		labels := map[string]string{controllerNameKey: controllerNameValue}
+		annotations := map[string]string{sourceHashAnnotation: hash}
+		annotations[metadataHashAnnotation] = metadataHash(labels, annotations)
+		annotations[sourceResourceVersionAnnotation] = resourceVersion
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "team-a", Labels: labels, Annotations: annotations},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       data,
+		}
+	}
+
+	It("should skip a copy that holds the same content and metadata", func() {
+		Expect(isUpToDate(newCopy("1"), newCopy("1"))).To(BeTrue())
+	})
+
+	It("should write a copy that names an older revision of the source", func() {
+		// the content is the same, but the copy must name the revision it was synced from
+		Expect(isUpToDate(newCopy("1"), newCopy("2"))).To(BeFalse())
+	})
+
+	It("should write a copy that is missing or was changed by someone else", func() {
+		Expect(isUpToDate(nil, newCopy("1"))).To(BeFalse())
+
+		edited := newCopy("1")
+		edited.Data = map[string][]byte{"password": []byte("changed")}
+		Expect(isUpToDate(edited, newCopy("1"))).To(BeFalse())
+
+		relabelled := newCopy("1")
+		delete(relabelled.Labels, controllerNameKey)
+		Expect(isUpToDate(relabelled, newCopy("1"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("pendingReconcileRequest", func() {
+	It("should return a reconcile-at value only until it is handled", func() {
+		instance := &syncv1alpha1.SecretSync{}
+		_, pending := pendingReconcileRequest(instance)
+		Expect(pending).To(BeFalse())
+
+		instance.Annotations = map[string]string{reconcileAtAnnotation: "2025-06-01T10:00:00Z"}
+		request, pending := pendingReconcileRequest(instance)
+		Expect(pending).To(BeTrue())
+		Expect(request).To(Equal("2025-06-01T10:00:00Z"))
+
+		instance.Status.LastHandledReconcileAt = request
+		_, pending = pendingReconcileRequest(instance)
+		Expect(pending).To(BeFalse())
+	})
+})
+
+var _ = Describe("syncSecretToNamespaces with reconcile-at", func() {
+	ctx := context.Background()
+	src := newSourceObject(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("s3cret")},
+	})
+
+	var instance *syncv1alpha1.SecretSync
+	BeforeEach(func() {
+		instance = &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "sync-db",
+				Namespace:   "default",
+				UID:         "uid-1",
+				Annotations: map[string]string{reconcileAtAnnotation: "2025-06-01T10:00:00Z"},
+			},
+			Spec: syncv1alpha1.SecretSyncSpec{SourceName: "db", SourceNamespace: "default", TargetNamespaces: []string{"team-a"}},
+		}
+		DeferCleanup(deleteInstanceMetrics, instance)
+	})
+
+	It("should not record the request while a target failed", func() {
+		instance.Spec.Template = &syncv1alpha1.TemplateSpec{Data: map[string]string{"url": "{{ .Data.missing }}"}}
+		_, err := newTestReconciler(instance).syncSecretToNamespaces(ctx, instance, src, []string{"team-a"}, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(instance.Status.Targets[0].Result).To(Equal(syncv1alpha1.TargetResultFailed))
+		Expect(instance.Status.LastHandledReconcileAt).To(BeEmpty())
+	})
+
+	It("should record the request once no target failed", func() {
+		denied := map[string]targetDenial{"team-a": {reason: exportNotAllowedReason, message: "not allowed"}}
+		_, err := newTestReconciler(instance).syncSecretToNamespaces(ctx, instance, src, []string{"team-a"}, denied)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Status.LastHandledReconcileAt).To(Equal("2025-06-01T10:00:00Z"))
+	})
+})
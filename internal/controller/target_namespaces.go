@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveTargetNamespaces - returns the list of namespaces the source secret should be copied to.
+// This is the union of spec.targetNamespaces and the namespaces matched by spec.targetNamespaceSelector.
+// The returned list is sorted and does not contain duplicates.
+func (r *SecretSyncReconciler) resolveTargetNamespaces(
+	ctx context.Context, // context for the API call
+	instance syncInstance, // the CR that called the reconcile function
+) ([]string, error) {
+
+	namespaces := slices.Clone(instance.GetSpec().TargetNamespaces)
+
+	if instance.GetSpec().TargetNamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(instance.GetSpec().TargetNamespaceSelector)
+		if err != nil {
+			return nil, permanent(fmt.Errorf("invalid targetNamespaceSelector: %w", err))
+		}
+
+		// read the namespaces that match the selector from our local cache
+		var nsList corev1.NamespaceList
+		if err := r.List(ctx, &nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("error listing namespaces matching targetNamespaceSelector: %w", err)
+		}
+
+		for _, ns := range nsList.Items {
+			// we never copy the source secret onto itself, so the source namespaces are skipped
+			// even if they match the selector
+			if instance.GetSpec().IsSourceNamespace(ns.Name) {
+				continue
+			}
+			// writes into a namespace that is being deleted will be rejected by the API server
+			if ns.Status.Phase == corev1.NamespaceTerminating {
+				continue
+			}
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+
+	slices.Sort(namespaces)
+	return slices.Compact(namespaces), nil
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("resolveTargetNamespaces", func() {
+	ctx := context.Background()
+	namespace := func(name string, labels map[string]string, phase corev1.NamespacePhase) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status:     corev1.NamespaceStatus{Phase: phase},
+		}
+	}
+	tenant := map[string]string{"tenant": "true"}
+
+	var instance *syncv1alpha1.SecretSync
+	BeforeEach(func() {
+		instance = &syncv1alpha1.SecretSync{Spec: syncv1alpha1.SecretSyncSpec{
+			SourceName:              "db",
+			SourceNamespace:         "default",
+			TargetNamespaces:        []string{"team-c", "team-a"},
+			TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: tenant},
+		}}
+	})
+
+	It("should add the matching namespaces, without the source namespace and terminating namespaces", func() {
+		r := newTestReconciler(
+			namespace("team-a", tenant, corev1.NamespaceActive),
+			namespace("team-b", tenant, corev1.NamespaceActive),
+			namespace("default", tenant, corev1.NamespaceActive),
+			namespace("leaving", tenant, corev1.NamespaceTerminating),
+			namespace("team-c", nil, corev1.NamespaceActive),
+			namespace("other", nil, corev1.NamespaceActive),
+		)
+		Expect(r.resolveTargetNamespaces(ctx, instance)).To(Equal([]string{"team-a", "team-b", "team-c"}))
+	})
+
+	It("should only return spec.targetNamespaces without a selector", func() {
+		instance.Spec.TargetNamespaceSelector = nil
+		r := newTestReconciler(namespace("team-b", tenant, corev1.NamespaceActive))
+		Expect(r.resolveTargetNamespaces(ctx, instance)).To(Equal([]string{"team-a", "team-c"}))
+	})
+
+	It("should report an invalid selector as a permanent error", func() {
+		instance.Spec.TargetNamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "tenant", Operator: "Bogus"},
+		}}
+		_, err := newTestReconciler().resolveTargetNamespaces(ctx, instance)
+		Expect(err).To(HaveOccurred())
+		Expect(isPermanent(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// templateError is returned when spec.template cannot be rendered, it is reported
+// with its own reason on the Synced condition because only the user can fix it
+type templateError struct {
+	key string // the key of the copy that failed to render
+	err error
+}
+
+func (e *templateError) Error() string {
+	return fmt.Sprintf("error rendering spec.template.data[%q]: %s", e.key, e.err)
+}
+
+func (e *templateError) Unwrap() error {
+	return e.err
+}
+
+// templateContext is the data the templates are executed against
+type templateContext struct {
+	Data     map[string]string // the data of the source, as strings
+	Metadata templateMetadata  // the metadata of the source
+}
+
+// templateMetadata is the metadata of the source that is visible to the templates
+type templateMetadata struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// templateFuncs are the sprig like helpers available to the templates
+var templateFuncs = template.FuncMap{
+	"b64enc": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"b64dec": func(s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		return string(b), err
+	},
+	"toJson": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"trim":       strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"replace":    func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+	"quote":      strconv.Quote,
+	// default returns value, or def if value is empty, so that it can be used at the end of a pipeline
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// renderTemplate - renders the keys of spec.template against the source
+// the templates see the whole source, so a key that is excluded by spec.data can still be used in a template
+func renderTemplate(
+	spec *syncv1alpha1.TemplateSpec, // spec.template of the instance, may be nil
+	src *sourceObject, // the source the templates are executed against
+) (map[string][]byte, error) {
+	if spec == nil || len(spec.Data) == 0 {
+		return nil, nil
+	}
+
+	ctx := templateContext{
+		Data: make(map[string]string, len(src.data)),
+		Metadata: templateMetadata{
+			Name:        src.GetName(),
+			Namespace:   src.GetNamespace(),
+			Labels:      src.GetLabels(),
+			Annotations: src.GetAnnotations(),
+		},
+	}
+	for k, v := range src.data {
+		ctx.Data[k] = string(v)
+	}
+
+	rendered := make(map[string][]byte, len(spec.Data))
+	// the keys are rendered in a fixed order so that the reported error does not change between reconciles
+	for _, key := range slices.Sorted(maps.Keys(spec.Data)) {
+		// a reference to a key the source does not have is an error and not an empty string,
+		// use index .Data "key" to read an optional key
+		tmpl, err := template.New(key).Funcs(templateFuncs).Option("missingkey=error").Parse(spec.Data[key])
+		if err != nil {
+			return nil, &templateError{key: key, err: err}
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, ctx); err != nil {
+			return nil, &templateError{key: key, err: err}
+		}
+		rendered[key] = buf.Bytes()
+	}
+	return rendered, nil
+}
@@ -18,6 +18,10 @@ package controller
 
 import (
 	"context"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -31,7 +35,7 @@ import (
 // mapSecretToSecretSyncs maps a Secret event to one or more SecretSync reconcile requests.
 // This function is called when any Secret is created or updated.
 //
-// It uses the field index "bySourceSecret" (sourceNamespace/sourceName) to efficiently find
+// It uses the field index "bySourceSecret" (kind/sourceNamespace/sourceName) to efficiently find
 // all SecretSync custom resources that reference the changed Secret as their source.
 //
 // For example:
@@ -40,33 +44,120 @@ import (
 //     spec.sourceName: "example-secret"
 //     spec.sourceNamespace: "test-source"
 //   - Each matching SecretSync is then re-queued for reconciliation to re-sync the source to targets
+//
+// If the Secret is a copy managed by a SecretSync, that SecretSync is re-queued as well, so that
+// a copy that is edited or deleted by someone else is restored right away.
 func (r *SecretSyncReconciler) mapSecretToSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
-	srcSecret, ok := obj.(*corev1.Secret)
-	if !ok {
-		// this is not a secret, so we don't care about this watch event, ideally this should not happen
+	return r.mapSourceToSyncs(ctx, &syncv1alpha1.SecretSyncList{}, obj)
+}
+
+// mapConfigMapToSecretSyncs maps a ConfigMap event to the SecretSyncs with sourceKind ConfigMap
+// that reference the changed ConfigMap as their source, see mapSecretToSecretSyncs.
+func (r *SecretSyncReconciler) mapConfigMapToSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapSourceToSyncs(ctx, &syncv1alpha1.SecretSyncList{}, obj)
+}
+
+// mapNamespaceToSecretSyncs maps a Namespace event to one or more SecretSync reconcile requests.
+// This function is called when a Namespace is created, deleted or its labels or annotations change.
+//
+// We cannot tell from the new labels alone if a namespace has stopped matching a selector,
+// so every SecretSync that uses spec.targetNamespaceSelector is re-queued. The reconciler
+// then works out if the namespace needs a copy of the secret or if an existing copy must be removed.
+// SecretSyncs that list the namespace explicitly in spec.targetNamespaces are also re-queued so that
+// a namespace that is created after the SecretSync gets the secret right away.
+// Annotation changes matter because of the accept-from annotation of the target namespace.
+func (r *SecretSyncReconciler) mapNamespaceToSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapNamespaceToSyncs(ctx, &syncv1alpha1.SecretSyncList{}, obj)
+}
+
+// mapOverlappingToSecretSyncs maps a SecretSync or ClusterSecretSync event to the SecretSyncs
+// that write one of its copies, see mapOverlappingToSyncs.
+func (r *SecretSyncReconciler) mapOverlappingToSecretSyncs(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.mapOverlappingToSyncs(ctx, &syncv1alpha1.SecretSyncList{}, obj)
+}
+
+// mapSourceToSyncs returns a reconcile request for every item of list that uses obj as its source,
+// and for the owner of obj if obj is a copy managed by the controller.
+// list decides whether SecretSyncs or ClusterSecretSyncs are looked up.
+func (r *SecretSyncReconciler) mapSourceToSyncs(
+	ctx context.Context,
+	list client.ObjectList, // an empty SecretSyncList or ClusterSecretSyncList
+	obj client.Object, // the changed Secret or ConfigMap
+) []ctrl.Request {
+	switch obj.(type) {
+	case *corev1.Secret, *corev1.ConfigMap:
+	default:
+		// this is not a secret or a configmap, so we don't care about this watch event, ideally this should not happen
 		return nil
 	}
 
-	var (
-		reqs           []ctrl.Request              // list of reconcile requests to be returned
-		syncSecretList syncv1alpha1.SecretSyncList // list of all SecretSync CRs in the cluster
-	)
+	// a copy we manage points to its owner through its labels and annotations
+	reqs := ownerRequests(list, obj)
 
-	// now we look into our index and see if there are CRs that point this source secret
+	// now we look into our index and see if there are CRs that point this source object
 	// we have created this index already
-	// from our cache we try to obtain all the CRs that point to the source secret
-	if err := r.List(ctx, &syncSecretList, client.MatchingFields{
-		bySourceSecretIndexKey: srcSecret.Name + "/" + srcSecret.Namespace,
+	// from our cache we try to obtain all the CRs that point to the source object
+	if err := r.List(ctx, list, client.MatchingFields{
+		bySourceSecretIndexKey: sourceIndexValue(kindOfObject(obj), obj.GetNamespace(), obj.GetName()),
 	}); err != nil {
-		return nil // on error do not requeue
+		return reqs // on error do not requeue
 	}
-	// iterate through all the syncSecrets that the secret that we watched points to
-	for _, syncSecret := range syncSecretList.Items {
-		reqs = append(reqs, ctrl.Request{NamespacedName: types.NamespacedName{
-			Name:      syncSecret.Name,
-			Namespace: syncSecret.Namespace,
-		}})
+	// iterate through all the CRs that the object that we watched points to
+	return append(reqs, requestsFor(list, func(syncInstance) bool { return true })...)
+}
+
+// ownerRequests returns a reconcile request for the owner of obj if obj is a copy managed by
+// the controller and its owner is of the kind list holds, ClusterSecretSyncs have no namespace.
+// The reconciler checks the owner-uid label, so a copy whose owner was recreated is not taken over.
+func ownerRequests(list client.ObjectList, obj client.Object) []ctrl.Request {
+	if obj.GetLabels()[controllerNameKey] != controllerNameValue {
+		return nil // not one of our copies
+	}
+	annotations := obj.GetAnnotations()
+	name, ok := annotations[controllerOwnerNameKey]
+	if !ok || name == "" {
+		return nil
+	}
+	namespace := annotations[controllerOwnerNamespacekey]
+	_, clusterScoped := list.(*syncv1alpha1.ClusterSecretSyncList)
+	if clusterScoped != (namespace == "") {
+		return nil // the copy belongs to the other kind
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
+// mapNamespaceToSyncs returns a reconcile request for every item of list that uses a
+// targetNamespaceSelector or lists the namespace in its targetNamespaces.
+func (r *SecretSyncReconciler) mapNamespaceToSyncs(
+	ctx context.Context,
+	list client.ObjectList, // an empty SecretSyncList or ClusterSecretSyncList
+	obj client.Object, // the changed Namespace
+) []ctrl.Request {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		// this is not a namespace, so we don't care about this watch event, ideally this should not happen
+		return nil
+	}
+
+	if err := r.List(ctx, list); err != nil {
+		return nil // on error do not requeue
 	}
 
+	return requestsFor(list, func(instance syncInstance) bool {
+		return instance.GetSpec().TargetNamespaceSelector != nil ||
+			slices.Contains(instance.GetSpec().TargetNamespaces, ns.Name)
+	})
+}
+
+// requestsFor - returns a reconcile request for every item of list that passes the filter
+func requestsFor(list client.ObjectList, filter func(syncInstance) bool) []ctrl.Request {
+	var reqs []ctrl.Request // list of reconcile requests to be returned
+	_ = meta.EachListItem(list, func(obj runtime.Object) error {
+		instance, ok := obj.(syncInstance)
+		if ok && filter(instance) {
+			reqs = append(reqs, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(instance)})
+		}
+		return nil
+	})
 	return reqs
 }
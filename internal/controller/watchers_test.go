@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// newTestReconciler - returns a reconciler backed by a fake client that holds objs,
+// the fake client has the field indexes the manager registers for the controllers
+func newTestReconciler(objs ...client.Object) *SecretSyncReconciler {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(syncv1alpha1.AddToScheme(scheme)).To(Succeed())
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithStatusSubresource(&syncv1alpha1.SecretSync{}, &syncv1alpha1.ClusterSecretSync{})
+	for _, obj := range []client.Object{&syncv1alpha1.SecretSync{}, &syncv1alpha1.ClusterSecretSync{}} {
+		builder = builder.
+			WithIndex(obj, bySourceSecretIndexKey, indexBySource).
+			WithIndex(obj, byTargetSecretIndexKey, indexByTarget)
+	}
+	return &SecretSyncReconciler{Client: builder.Build(), Scheme: scheme}
+}
+
+// requestFor - returns the reconcile request of the SecretSync namespace/name
+func requestFor(namespace, name string) ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}
+}
+
+var _ = Describe("mapNamespaceToSyncs", func() {
+	ctx := context.Background()
+
+	It("should re-queue the SecretSyncs that select namespaces or list the namespace", func() {
+		r := newTestReconciler(
+			&syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "selector", Namespace: "default"},
+				Spec: syncv1alpha1.SecretSyncSpec{
+					TargetNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				},
+			},
+			&syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "listed", Namespace: "default"},
+				Spec:       syncv1alpha1.SecretSyncSpec{TargetNamespaces: []string{"team-b", "team-a"}},
+			},
+			&syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+				Spec:       syncv1alpha1.SecretSyncSpec{TargetNamespaces: []string{"team-b"}},
+			},
+		)
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+		Expect(r.mapNamespaceToSecretSyncs(ctx, namespace)).To(ConsistOf(
+			requestFor("default", "selector"), requestFor("default", "listed"),
+		))
+	})
+
+	It("should ignore objects that are not namespaces", func() {
+		r := newTestReconciler()
+		Expect(r.mapNamespaceToSecretSyncs(ctx, &corev1.Secret{})).To(BeEmpty())
+	})
+})
+
+var _ = Describe("mapConfigMapToSecretSyncs", func() {
+	ctx := context.Background()
+
+	It("should re-queue the SecretSyncs that read the ConfigMap and the owner of a copy", func() {
+		owner := &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "platform", UID: "uid-1"},
+		}
+		r := newTestReconciler(
+			&syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "default"},
+				Spec: syncv1alpha1.SecretSyncSpec{
+					SourceKind: syncv1alpha1.SourceKindConfigMap, SourceName: "settings", SourceNamespace: "default",
+				},
+			},
+			&syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"},
+				Spec:       syncv1alpha1.SecretSyncSpec{SourceName: "settings", SourceNamespace: "default"},
+			},
+		)
+
+		source := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}}
+		Expect(r.mapConfigMapToSecretSyncs(ctx, source)).To(ConsistOf(requestFor("default", "configmap")))
+
+		copied := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "settings", Namespace: "team-a",
+			Labels: ownershipLabels(owner), Annotations: ownershipAnnotations(owner),
+		}}
+		Expect(r.mapConfigMapToSecretSyncs(ctx, copied)).To(ConsistOf(requestFor("platform", "owner")))
+	})
+})
@@ -0,0 +1,256 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+// log is for logging in this package.
+var secretsynclog = logf.Log.WithName("secretsync-resource")
+
+// SetupSecretSyncWebhookWithManager registers the webhook for SecretSync in the manager.
+func SetupSecretSyncWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&syncv1alpha1.SecretSync{}).
+		WithValidator(&SecretSyncCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-sync-example-com-v1alpha1-secretsync,mutating=false,failurePolicy=fail,sideEffects=None,groups=sync.example.com,resources=secretsyncs,verbs=create;update,versions=v1alpha1,name=vsecretsync-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// SecretSyncCustomValidator rejects SecretSyncs that the controller could never sync, so the
+// mistake is reported by kubectl instead of showing up later in the status of the SecretSync.
+// It checks the names in the spec, that the source is not copied onto itself, that no target
+// namespace is listed twice and that no other SecretSync or ClusterSecretSync already writes
+// the same copy. Namespaces matched by targetNamespaceSelector are only known at reconcile time,
+// so overlaps through a selector are left to the controller.
+type SecretSyncCustomValidator struct {
+	// Client reads the other SecretSyncs and ClusterSecretSyncs to find overlapping targets
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &SecretSyncCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type SecretSync.
+func (v *SecretSyncCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	secretsync, ok := obj.(*syncv1alpha1.SecretSync)
+	if !ok {
+		return nil, fmt.Errorf("expected a SecretSync object but got %T", obj)
+	}
+	secretsynclog.Info("Validation for SecretSync upon creation", "name", secretsync.GetName())
+
+	return nil, v.validateSecretSync(ctx, secretsync, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type SecretSync.
+func (v *SecretSyncCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	secretsync, ok := newObj.(*syncv1alpha1.SecretSync)
+	if !ok {
+		return nil, fmt.Errorf("expected a SecretSync object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*syncv1alpha1.SecretSync)
+	if !ok {
+		return nil, fmt.Errorf("expected a SecretSync object for the oldObj but got %T", oldObj)
+	}
+	secretsynclog.Info("Validation for SecretSync upon update", "name", secretsync.GetName())
+
+	// a SecretSync that is being deleted must stay updatable, or its finalizer could never be removed
+	if !secretsync.GetDeletionTimestamp().IsZero() {
+		return nil, nil
+	}
+	return nil, v.validateSecretSync(ctx, secretsync, old)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type SecretSync.
+func (v *SecretSyncCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateSecretSync - returns an Invalid error that lists every problem of the SecretSync, or nil
+// old is the SecretSync before an update, nil on create
+func (v *SecretSyncCustomValidator) validateSecretSync(
+	ctx context.Context, // context for the API call
+	secretsync *syncv1alpha1.SecretSync, // the SecretSync that is being admitted
+	old *syncv1alpha1.SecretSync, // the SecretSync before the update, nil on create
+) error {
+	allErrs := validateSpec(&secretsync.Spec, field.NewPath("spec"))
+
+	// overlaps are only looked for once the spec is valid on its own, the names of the copies
+	// cannot be worked out from a spec with invalid names
+	if len(allErrs) == 0 {
+		overlapErrs, err := v.validateTargetsNotOverlapping(ctx, secretsync, old, field.NewPath("spec"))
+		if err != nil {
+			return err
+		}
+		allErrs = append(allErrs, overlapErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(syncv1alpha1.GroupVersion.WithKind("SecretSync").GroupKind(), secretsync.Name, allErrs)
+}
+
+// validateSpec - checks the names in the spec and the list of target namespaces
+func validateSpec(spec *syncv1alpha1.SecretSyncSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// the schema requires exactly one of sourceName and sources
+	if spec.SourceName != "" {
+		allErrs = append(allErrs, validateName(specPath.Child("sourceName"), spec.SourceName, validation.IsDNS1123Subdomain)...)
+	}
+	allErrs = append(allErrs, validateName(specPath.Child("sourceNamespace"), spec.SourceNamespace, validation.IsDNS1123Label)...)
+	for i, ref := range spec.Sources {
+		path := specPath.Child("sources").Index(i)
+		allErrs = append(allErrs, validateName(path.Child("name"), ref.Name, validation.IsDNS1123Subdomain)...)
+		if ref.Namespace != "" {
+			allErrs = append(allErrs, validateName(path.Child("namespace"), ref.Namespace, validation.IsDNS1123Label)...)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(spec.TargetNamespaces))
+	for i, ns := range spec.TargetNamespaces {
+		path := specPath.Child("targetNamespaces").Index(i)
+		allErrs = append(allErrs, validateName(path, ns, validation.IsDNS1123Label)...)
+		if ns == spec.SourceNamespace {
+			allErrs = append(allErrs, field.Invalid(path, ns, "must not be the sourceNamespace, the source cannot be copied onto itself"))
+		} else if spec.IsSourceNamespace(ns) {
+			allErrs = append(allErrs, field.Invalid(path, ns, "must not be the namespace of one of the sources, a source cannot be copied onto itself"))
+		}
+		if _, ok := seen[ns]; ok {
+			allErrs = append(allErrs, field.Duplicate(path, ns))
+		}
+		seen[ns] = struct{}{}
+	}
+
+	// the overrides are checked in a stable order so the same spec always returns the same errors
+	overrides := make([]string, 0, len(spec.TargetNameOverrides))
+	for ns := range spec.TargetNameOverrides {
+		overrides = append(overrides, ns)
+	}
+	slices.Sort(overrides)
+	for _, ns := range overrides {
+		path := specPath.Child("targetNameOverrides").Key(ns)
+		allErrs = append(allErrs, validateName(path, ns, validation.IsDNS1123Label)...)
+		allErrs = append(allErrs, validateName(path, spec.TargetNameOverrides[ns], validation.IsDNS1123Subdomain)...)
+	}
+
+	return allErrs
+}
+
+// validateName - returns an Invalid error for the field if isValid reports any problem with the name
+func validateName(path *field.Path, name string, isValid func(string) []string) field.ErrorList {
+	if msgs := isValid(name); len(msgs) > 0 {
+		return field.ErrorList{field.Invalid(path, name, strings.Join(msgs, ", "))}
+	}
+	return nil
+}
+
+// validateTargetsNotOverlapping - returns a Forbidden error for every target namespace whose copy is
+// already written by another SecretSync or ClusterSecretSync
+// on update only the copies the SecretSync did not target before are checked, two SecretSyncs that already
+// overlap, because they existed before the webhook or were admitted at the same time, are arbitrated by
+// the controller and must stay updatable, for example to suspend them or to add the finalizer
+func (v *SecretSyncCustomValidator) validateTargetsNotOverlapping(
+	ctx context.Context, // context for the API call
+	secretsync *syncv1alpha1.SecretSync, // the SecretSync that is being admitted
+	old *syncv1alpha1.SecretSync, // the SecretSync before the update, nil on create
+	specPath *field.Path, // path of the spec in the errors
+) (field.ErrorList, error) {
+	if v.Client == nil || len(secretsync.Spec.TargetNamespaces) == 0 {
+		return nil, nil
+	}
+
+	// owners maps every copy that another CR writes to that CR, for example SecretSync team-a/db
+	owners := map[types.NamespacedName]string{}
+
+	var secretSyncs syncv1alpha1.SecretSyncList
+	if err := v.Client.List(ctx, &secretSyncs); err != nil {
+		return nil, fmt.Errorf("error listing SecretSyncs: %w", err)
+	}
+	for i := range secretSyncs.Items {
+		other := &secretSyncs.Items[i]
+		if other.Namespace == secretsync.Namespace && other.Name == secretsync.Name {
+			continue
+		}
+		addTargets(owners, &secretsync.Spec, &other.Spec, other.DeletionTimestamp.IsZero(),
+			fmt.Sprintf("SecretSync %s/%s", other.Namespace, other.Name))
+	}
+
+	var clusterSecretSyncs syncv1alpha1.ClusterSecretSyncList
+	if err := v.Client.List(ctx, &clusterSecretSyncs); err != nil {
+		return nil, fmt.Errorf("error listing ClusterSecretSyncs: %w", err)
+	}
+	for i := range clusterSecretSyncs.Items {
+		other := &clusterSecretSyncs.Items[i]
+		addTargets(owners, &secretsync.Spec, &other.Spec, other.DeletionTimestamp.IsZero(),
+			fmt.Sprintf("ClusterSecretSync %s", other.Name))
+	}
+
+	// the copies the SecretSync already wrote before the update
+	existing := map[types.NamespacedName]string{}
+	if old != nil {
+		addTargets(existing, &secretsync.Spec, &old.Spec, true, "")
+	}
+
+	var allErrs field.ErrorList
+	for i, ns := range secretsync.Spec.TargetNamespaces {
+		target := types.NamespacedName{Namespace: ns, Name: secretsync.Spec.TargetNameFor(ns)}
+		if _, ok := existing[target]; ok {
+			continue
+		}
+		if owner, ok := owners[target]; ok {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("targetNamespaces").Index(i),
+				fmt.Sprintf("%s %s in namespace %s is already synced by %s",
+					secretsync.Spec.Kind(), target.Name, target.Namespace, owner)))
+		}
+	}
+	return allErrs, nil
+}
+
+// addTargets - records the copies that other writes in its target namespaces, copies of another kind
+// never collide with the copies of spec, and a CR that is being deleted is about to release its copies
+func addTargets(
+	owners map[types.NamespacedName]string, // the copies written by other CRs
+	spec *syncv1alpha1.SecretSyncSpec, // the spec that is being admitted
+	other *syncv1alpha1.SecretSyncSpec, // the spec of another CR
+	active bool, // false if the other CR is being deleted
+	owner string, // the other CR, as it is named in the errors
+) {
+	if !active || other.Kind() != spec.Kind() {
+		return
+	}
+	for _, ns := range other.TargetNamespaces {
+		owners[types.NamespacedName{Namespace: ns, Name: other.TargetNameFor(ns)}] = owner
+	}
+}
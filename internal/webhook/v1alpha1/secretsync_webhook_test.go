@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	syncv1alpha1 "github.com/prit342/secret-sync-controller/api/v1alpha1"
+)
+
+var _ = Describe("SecretSync Webhook", func() {
+	var (
+		obj       *syncv1alpha1.SecretSync
+		validator SecretSyncCustomValidator
+	)
+
+	BeforeEach(func() {
+		obj = &syncv1alpha1.SecretSync{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec: syncv1alpha1.SecretSyncSpec{
+				SourceName:       "db-credentials",
+				SourceNamespace:  "default",
+				TargetNamespaces: []string{"team-a", "team-b"},
+			},
+		}
+		validator = SecretSyncCustomValidator{}
+	})
+
+	Context("When creating or updating SecretSync under Validating Webhook", func() {
+		It("Should admit a valid spec", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny the source namespace as a target", func() {
+			obj.Spec.TargetNamespaces = append(obj.Spec.TargetNamespaces, "default")
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[2]")))
+			Expect(err).To(MatchError(ContainSubstring("must not be the sourceNamespace")))
+		})
+
+		It("Should deny duplicate target namespaces", func() {
+			obj.Spec.TargetNamespaces = append(obj.Spec.TargetNamespaces, "team-a")
+			_, err := validator.ValidateUpdate(ctx, obj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[2]: Duplicate value")))
+		})
+
+		It("Should deny names that are not valid DNS-1123 names", func() {
+			obj.Spec.SourceName = "DB_Credentials"
+			obj.Spec.TargetNamespaces = []string{"Team-A"}
+			obj.Spec.TargetNameOverrides = map[string]string{"team-b": "app_secret"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.sourceName")))
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[0]")))
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNameOverrides[team-b]")))
+		})
+
+		It("Should validate the names of spec.sources", func() {
+			obj.Spec.SourceName = ""
+			obj.Spec.TargetName = "app"
+			obj.Spec.Sources = []syncv1alpha1.SourceRef{{Name: "db"}, {Name: "Cache_Creds", Namespace: "Platform"}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.sources[1].name")))
+			Expect(err).To(MatchError(ContainSubstring("spec.sources[1].namespace")))
+			Expect(err).NotTo(MatchError(ContainSubstring("spec.sourceName")))
+		})
+
+		It("Should deny the namespace of a source as a target", func() {
+			obj.Spec.SourceName = ""
+			obj.Spec.TargetName = "app"
+			obj.Spec.Sources = []syncv1alpha1.SourceRef{{Name: "db"}, {Name: "cache", Namespace: "team-b"}}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[1]")))
+			Expect(err).To(MatchError(ContainSubstring("must not be the namespace of one of the sources")))
+		})
+
+		It("Should deny a copy that another SecretSync already writes", func() {
+			other := &syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "platform"},
+				Spec: syncv1alpha1.SecretSyncSpec{
+					SourceName:          "shared",
+					SourceNamespace:     "platform",
+					TargetNamespaces:    []string{"team-b"},
+					TargetNameOverrides: map[string]string{"team-b": "db-credentials"},
+				},
+			}
+			validator.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(other).Build()
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[1]")))
+			Expect(err).To(MatchError(ContainSubstring("already synced by SecretSync platform/other")))
+
+			By("admitting the copy when the other SecretSync syncs ConfigMaps")
+			other.Spec.SourceKind = syncv1alpha1.SourceKindConfigMap
+			validator.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(other).Build()
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should only deny the overlaps an update adds", func() {
+			other := &syncv1alpha1.SecretSync{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "platform"},
+				Spec: syncv1alpha1.SecretSyncSpec{
+					SourceName:       "db-credentials",
+					SourceNamespace:  "platform",
+					TargetNamespaces: []string{"team-b", "team-c"},
+				},
+			}
+			validator.Client = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(other).Build()
+
+			By("admitting an update of a SecretSync that already overlapped")
+			updated := obj.DeepCopy()
+			updated.Spec.Suspend = true
+			Expect(validator.ValidateUpdate(ctx, obj, updated)).To(BeNil())
+
+			By("denying a target namespace the update adds")
+			updated.Spec.TargetNamespaces = append(updated.Spec.TargetNamespaces, "team-c")
+			_, err := validator.ValidateUpdate(ctx, obj, updated)
+			Expect(err).To(MatchError(ContainSubstring("spec.targetNamespaces[2]")))
+			Expect(err).NotTo(MatchError(ContainSubstring("spec.targetNamespaces[1]")))
+		})
+	})
+})